@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/romainframe/grunter/pkg/cmds"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for the diff command.
+var (
+	// ErrDiffConfig is returned when the pending changes cannot be computed.
+	ErrDiffConfig = fmt.Errorf("⛔️ command 'diff' failed")
+
+	// ErrPendingChanges is returned in check mode when generated files are out of date.
+	ErrPendingChanges = func(count int) error {
+		return fmt.Errorf("⛔️ %d generated file(s) out of date, run 'grunter gen' to update them", count)
+	}
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [path]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Show the changes 'gen' would make to the files on disk",
+	Long: `Show the changes 'gen' would make to the files on disk.
+
+This command renders the Terragrunt configuration files in memory and prints a
+unified diff for every file that 'gen' would create or overwrite. Nothing is
+written to disk. With --check, the command exits with a non-zero status when
+at least one file differs, which lets CI catch generated files that were edited
+by hand or not regenerated.
+
+With --recursive, every block.yaml, system.yaml, overlay.yaml and instance.yaml
+found under path (the current directory by default) is compared.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Extract flag values
+		inputPath, _ := cmd.Flags().GetString("input")
+		outputPath, _ := cmd.Flags().GetString("output")
+		check, _ := cmd.Flags().GetBool("check")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		config, err := initProject(cmd)
+		if err != nil {
+			return utils.WrapError(ErrDiffConfig, err)
+		}
+
+		root, err := recursiveRoot(args, recursive)
+		if err != nil {
+			return utils.WrapError(ErrDiffConfig, err)
+		}
+		if recursive {
+			return runDiffRecursive(config, root, outputPath, check)
+		}
+
+		return runDiff(config, inputPath, outputPath, check)
+	},
+}
+
+// runDiff prints the pending changes and, in check mode, fails when there is at least one.
+func runDiff(config cmds.Config, inputPath, outputPath string, check bool) error {
+	diffs, err := cmds.Diff(config, inputPath, outputPath)
+	if err != nil {
+		return utils.WrapError(ErrDiffConfig, err)
+	}
+
+	for _, d := range diffs {
+		fmt.Print(d.Diff)
+	}
+
+	if len(diffs) == 0 {
+		fmt.Println("✅ generated files are up to date")
+		return nil
+	}
+	if check {
+		return ErrPendingChanges(len(diffs))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("input", "i", "", "Path to the input configuration file")
+	diffCmd.Flags().StringP("output", "o", "terragrunt.hcl", "Path for the output Terragrunt configuration file (default is current directory)")
+	diffCmd.Flags().Bool("check", false, "Exit with a non-zero status when generated files differ from the files on disk")
+	diffCmd.Flags().BoolP("recursive", "r", false, "Compare every grunter object found under path")
+	diffCmd.Flags().Bool("strict", false, "Reject unknown keys even for apiVersion v1 objects")
+	addTemplateFlags(diffCmd)
+	addSetFlag(diffCmd)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/romainframe/grunter/pkg/cmds"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for the explain command.
+var (
+	// ErrExplainConfig is returned when the fields of a block cannot be explained.
+	ErrExplainConfig = fmt.Errorf("⛔️ command 'explain' failed")
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Args:  cobra.NoArgs,
+	Short: "Show which file each field of a block comes from",
+	Long: `Show which file each field of a block comes from.
+
+For every block of the input file, this command prints the files it is merged
+from, following 'extends' from the furthest file to the one defining the block,
+then every field of the block with its value and the file that sets it. Fields
+inherited from the defaults of a system are attributed to the system file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Extract flag values
+		inputPath, _ := cmd.Flags().GetString("input")
+		config, err := initProject(cmd)
+		if err != nil {
+			return utils.WrapError(ErrExplainConfig, err)
+		}
+
+		explanations, err := cmds.Explain(config, inputPath)
+		if err != nil {
+			return utils.WrapErrors(ErrExplainConfig, err)
+		}
+
+		for i, e := range explanations {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("📦 %s\n", e.Block)
+			fmt.Printf("   files: %s\n", strings.Join(e.Files, " → "))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, f := range e.Fields {
+				value, _ := json.Marshal(f.Value)
+				fmt.Fprintf(w, "   %s\t%s\t%s\n", f.Path, value, f.File)
+			}
+			if err := w.Flush(); err != nil {
+				return utils.WrapError(ErrExplainConfig, err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringP("input", "i", "", "Path to the input configuration file")
+	explainCmd.Flags().Bool("strict", false, "Reject unknown keys even for apiVersion v1 objects")
+}
@@ -2,13 +2,10 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/romainframe/grunter/pkg/cmds"
-	"github.com/romainframe/grunter/pkg/env"
 	"github.com/romainframe/grunter/pkg/utils"
 )
 
@@ -20,32 +17,53 @@ var (
 
 // genCmd represents the grunt command
 var genCmd = &cobra.Command{
-	Use: "gen",
+	Use:  "gen [path]",
+	Args: cobra.MaximumNArgs(1),
 	// Version: grunter.Version,
 	Short: "Generate Terragrunt configuration files",
 	Long: `Generate Terragrunt configuration files based on the provided config input.
 
 This command processes a JSON or YAML file containing the necessary configuration
-information and generates a corresponding Terragrunt configuration file.`,
+information and generates a corresponding Terragrunt configuration file.
+
+With --recursive, every block.yaml, system.yaml, overlay.yaml and instance.yaml
+found under path (the current directory by default) is generated relative to
+its own directory. The .git and .terragrunt-cache directories and the paths
+listed in .gitignore files are skipped.
+
+The parameters of Blueprints can be set with --set name=value, which overrides
+the values of Instances and the defaults of Blueprints. With --recursive, each
+Blueprint only takes the parameters it declares, and a parameter declared by
+none of them is an error.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Extract flag values
 		inputPath, _ := cmd.Flags().GetString("input")
 		outputPath, _ := cmd.Flags().GetString("output")
-
-		// Get repoRoot from env variable GRUNT_REPO_ROOT
-		repoRoot := os.Getenv("GRUNT_REPO_ROOT")
-		if repoRoot == "" {
-			return utils.WrapError(ErrGenConfig, fmt.Errorf("environment variable GRUNT_REPO_ROOT not set"))
+		check, _ := cmd.Flags().GetBool("check")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		config, err := initProject(cmd)
+		if err != nil {
+			return utils.WrapError(ErrGenConfig, err)
 		}
 
-		repoRoot, err := filepath.Abs(repoRoot)
+		root, err := recursiveRoot(args, recursive)
 		if err != nil {
 			return utils.WrapError(ErrGenConfig, err)
 		}
 
-		env.GRUNT_REPO_ROOT = repoRoot
+		// In check mode, only report the pending changes.
+		if check {
+			if recursive {
+				return runDiffRecursive(config, root, outputPath, true)
+			}
+			return runDiff(config, inputPath, outputPath, true)
+		}
+
+		if recursive {
+			return runGenRecursive(config, root, outputPath)
+		}
 
-		generatedFiles, err := cmds.Gen(inputPath, outputPath)
+		generatedFiles, err := cmds.Gen(config, inputPath, outputPath)
 		if err != nil {
 			return utils.WrapError(ErrGenConfig, err)
 		}
@@ -64,4 +82,9 @@ func init() {
 	// Here we define the flags for genCmd
 	genCmd.Flags().StringP("input", "i", "", "Path to the input configuration file")
 	genCmd.Flags().StringP("output", "o", "terragrunt.hcl", "Path for the output Terragrunt configuration file (default is current directory)")
+	genCmd.Flags().Bool("check", false, "Do not write anything, print the pending changes and exit with a non-zero status if there are any")
+	genCmd.Flags().BoolP("recursive", "r", false, "Generate every grunter object found under path")
+	genCmd.Flags().Bool("strict", false, "Reject unknown keys even for apiVersion v1 objects")
+	addTemplateFlags(genCmd)
+	addSetFlag(genCmd)
 }
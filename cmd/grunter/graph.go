@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/romainframe/grunter/pkg/cmds"
+	pkggrunter "github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for the graph command.
+var (
+	// ErrGraphConfig is returned when the dependency graph cannot be exported.
+	ErrGraphConfig = fmt.Errorf("⛔️ command 'graph' failed")
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph [path]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Print the dependency graph of the generated configurations",
+	Long: `Print the dependency graph of the generated configurations.
+
+This command builds the Terragrunt configurations in memory, without writing
+anything, and prints the dependencies between them in the DOT, Mermaid or JSON
+format. Nodes are labelled with the template and the metadata of their block;
+dependencies on directories that are not generated are shown as external nodes.
+With --collapse-systems, the blocks of a system are shown as a single node.
+
+With --recursive, the graph of every block.yaml, system.yaml, overlay.yaml and
+instance.yaml found under path (the current directory by default) is printed,
+with the dependencies between objects.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Extract flag values
+		inputPath, _ := cmd.Flags().GetString("input")
+		outputPath, _ := cmd.Flags().GetString("output")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		format, _ := cmd.Flags().GetString("format")
+		collapse, _ := cmd.Flags().GetBool("collapse-systems")
+		config, err := initProject(cmd)
+		if err != nil {
+			return utils.WrapError(ErrGraphConfig, err)
+		}
+
+		root, err := recursiveRoot(args, recursive)
+		if err != nil {
+			return utils.WrapError(ErrGraphConfig, err)
+		}
+
+		var graph pkggrunter.Graph
+		if recursive {
+			graph, err = graphRecursive(config, root, outputPath)
+		} else {
+			graph, err = cmds.Graph(config, inputPath, outputPath)
+		}
+		if err != nil {
+			return utils.WrapError(ErrGraphConfig, err)
+		}
+
+		export, err := graph.Export(collapse)
+		if err != nil {
+			return utils.WrapError(ErrGraphConfig, err)
+		}
+		if err := export.Write(os.Stdout, format); err != nil {
+			return utils.WrapError(ErrGraphConfig, err)
+		}
+		return nil
+	},
+}
+
+// graphRecursive merges the graphs of every grunter object found under root. Failed objects are
+// reported on the standard error and fail the command once all of them are reported.
+func graphRecursive(config cmds.Config, root, outputPath string) (pkggrunter.Graph, error) {
+	results, err := cmds.GraphRecursive(config, root, outputPath)
+	if err != nil && results == nil {
+		return pkggrunter.Graph{}, err
+	}
+
+	var graph pkggrunter.Graph
+	for _, r := range results {
+		if r.Err != nil {
+			printFailedResult(r)
+			continue
+		}
+		graph = graph.Merge(r.Graph)
+	}
+
+	if failed := cmds.FailedResults(results); failed > 0 {
+		return pkggrunter.Graph{}, cmds.ErrRecursive(failed, len(results))
+	}
+	if err != nil {
+		return pkggrunter.Graph{}, err
+	}
+	return graph, nil
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringP("input", "i", "", "Path to the input configuration file")
+	graphCmd.Flags().StringP("output", "o", "terragrunt.hcl", "Path for the output Terragrunt configuration file (default is current directory)")
+	graphCmd.Flags().BoolP("recursive", "r", false, "Print the graph of every grunter object found under path")
+	graphCmd.Flags().String("format", pkggrunter.GraphFormatDOT, "Output format: dot, mermaid or json")
+	graphCmd.Flags().Bool("collapse-systems", false, "Show the blocks of a system as a single node")
+	graphCmd.Flags().Bool("strict", false, "Reject unknown keys even for apiVersion v1 objects")
+	addTemplateFlags(graphCmd)
+	addSetFlag(graphCmd)
+}
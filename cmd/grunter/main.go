@@ -3,8 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/romainframe/grunter"
+	"github.com/romainframe/grunter/pkg/cmds"
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/project"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +22,100 @@ var rootCmd = &cobra.Command{
 	Short:         "Grunter is a tool to generate Terragrunt configurations",
 }
 
+func init() {
+	rootCmd.PersistentFlags().IntP("jobs", "j", env.GRUNT_JOBS, "Maximum number of blocks, files or objects processed concurrently")
+}
+
+// initProject loads the project configuration and returns the settings of cmd, in increasing
+// order of precedence: built-in defaults, the .grunter.yaml file at the repository root, the
+// GRUNT_* environment variables, then the command-line flags.
+// The repository root is GRUNT_REPO_ROOT when set, and is detected from the working directory
+// otherwise (see project.FindRoot).
+func initProject(cmd *cobra.Command) (cmds.Config, error) {
+	repoRoot := os.Getenv("GRUNT_REPO_ROOT")
+	if repoRoot == "" {
+		var err error
+		if repoRoot, err = project.FindRoot("."); err != nil {
+			return cmds.Config{}, err
+		}
+	}
+	repoRoot, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return cmds.Config{}, err
+	}
+
+	projectConfig, err := project.Load(repoRoot)
+	if err != nil {
+		return cmds.Config{}, err
+	}
+	config, err := cmds.NewConfig(projectConfig)
+	if err != nil {
+		return cmds.Config{}, err
+	}
+	env.GRUNT_REPO_ROOT = projectConfig.Root
+	env.GRUNT_STRICT = projectConfig.Strict
+	if projectConfig.Jobs > 0 {
+		env.GRUNT_JOBS = projectConfig.Jobs
+	}
+
+	// Environment variables override the project configuration.
+	if value := os.Getenv("GRUNT_STRICT"); value != "" {
+		if env.GRUNT_STRICT, err = strconv.ParseBool(value); err != nil {
+			return cmds.Config{}, fmt.Errorf("invalid GRUNT_STRICT '%s': %w", value, err)
+		}
+	}
+	if value := os.Getenv("GRUNT_JOBS"); value != "" {
+		if env.GRUNT_JOBS, err = strconv.Atoi(value); err != nil {
+			return cmds.Config{}, fmt.Errorf("invalid GRUNT_JOBS '%s': %w", value, err)
+		}
+	}
+
+	// Flags override everything else, when set.
+	if cmd.Flags().Changed("strict") {
+		env.GRUNT_STRICT, _ = cmd.Flags().GetBool("strict")
+	}
+	if cmd.Flags().Changed("jobs") {
+		env.GRUNT_JOBS, _ = cmd.Flags().GetInt("jobs")
+	}
+	if cmd.Flags().Changed("template") {
+		path, _ := cmd.Flags().GetString("template")
+		if config.Grunter.Templates.Terragrunt, err = filepath.Abs(path); err != nil {
+			return cmds.Config{}, err
+		}
+	}
+	if cmd.Flags().Changed("values-template") {
+		path, _ := cmd.Flags().GetString("values-template")
+		if config.Grunter.Templates.Values, err = filepath.Abs(path); err != nil {
+			return cmds.Config{}, err
+		}
+	}
+	if cmd.Flags().Changed("set") {
+		values, _ := cmd.Flags().GetStringArray("set")
+		for _, value := range values {
+			name, text, ok := strings.Cut(value, "=")
+			if !ok || name == "" {
+				return cmds.Config{}, fmt.Errorf("invalid --set '%s', expected name=value", value)
+			}
+			config.Grunter.Parameters[name] = text
+		}
+	}
+	if env.GRUNT_JOBS < 1 {
+		return cmds.Config{}, fmt.Errorf("--jobs must be at least 1")
+	}
+	return config, nil
+}
+
+// addTemplateFlags adds the flags overriding the output templates to cmd.
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("template", "", "Path to a terragrunt.hcl template, possibly only redefining some partials")
+	cmd.Flags().String("values-template", "", "Path to a values.hcl template")
+}
+
+// addSetFlag adds the flag setting the parameters of Blueprints to cmd.
+func addSetFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("set", nil, "Set a Blueprint parameter, as name=value (can be repeated)")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
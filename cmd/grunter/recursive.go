@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/romainframe/grunter/pkg/cmds"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// recursiveRoot returns the root of a recursive run: the path argument if any, or the
+// current directory. A path argument is only accepted in recursive mode.
+func recursiveRoot(args []string, recursive bool) (string, error) {
+	if len(args) == 0 {
+		return ".", nil
+	}
+	if !recursive {
+		return "", fmt.Errorf("a path argument requires --recursive")
+	}
+	return args[0], nil
+}
+
+// runGenRecursive generates every grunter object found under root and prints an aggregated report.
+func runGenRecursive(config cmds.Config, root, outputPath string) error {
+	results, err := cmds.GenRecursive(config, root, outputPath)
+	if err != nil && results == nil {
+		return utils.WrapError(ErrGenConfig, err)
+	}
+
+	generated := 0
+	for _, r := range results {
+		if r.Err != nil {
+			printFailedResult(r)
+			continue
+		}
+		for _, f := range r.GeneratedFiles {
+			fmt.Printf("🎉 Terragrunt configuration successfully generated at '%s'\n", f)
+		}
+		generated += len(r.GeneratedFiles)
+	}
+
+	failed := cmds.FailedResults(results)
+	fmt.Printf("📊 %d object(s) processed, %d file(s) generated, %d failure(s)\n", len(results), generated, failed)
+	if failed > 0 {
+		return utils.WrapError(ErrGenConfig, cmds.ErrRecursive(failed, len(results)))
+	}
+	if err != nil {
+		return utils.WrapError(ErrGenConfig, err)
+	}
+	return nil
+}
+
+// runDiffRecursive prints the pending changes of every grunter object found under root and,
+// in check mode, fails when there is at least one.
+func runDiffRecursive(config cmds.Config, root, outputPath string, check bool) error {
+	results, err := cmds.DiffRecursive(config, root, outputPath)
+	if err != nil && results == nil {
+		return utils.WrapError(ErrDiffConfig, err)
+	}
+
+	pending := 0
+	for _, r := range results {
+		if r.Err != nil {
+			printFailedResult(r)
+			continue
+		}
+		if len(r.Diffs) > 0 {
+			fmt.Printf("📂 %s\n", r.InputPath)
+		}
+		for _, d := range r.Diffs {
+			fmt.Print(d.Diff)
+		}
+		pending += len(r.Diffs)
+	}
+
+	failed := cmds.FailedResults(results)
+	fmt.Printf("📊 %d object(s) processed, %d file(s) out of date, %d failure(s)\n", len(results), pending, failed)
+	if failed > 0 {
+		return utils.WrapError(ErrDiffConfig, cmds.ErrRecursive(failed, len(results)))
+	}
+	if err != nil {
+		return utils.WrapError(ErrDiffConfig, err)
+	}
+	if check && pending > 0 {
+		return ErrPendingChanges(pending)
+	}
+	return nil
+}
+
+// printFailedResult prints every problem raised by a failed object, prefixed with the path of the
+// object file unless the problem is located in a file already.
+func printFailedResult(r cmds.Result) {
+	for _, problem := range utils.ErrorList(r.Err) {
+		var fieldErr *utils.FieldError
+		if errors.As(problem, &fieldErr) && fieldErr.Pos.File != "" {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", problem)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "❌ %s: %s\n", r.InputPath, problem)
+	}
+}
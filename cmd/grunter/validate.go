@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/romainframe/grunter/pkg/cmds"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for the validate command.
+var (
+	// ErrValidateConfig is returned when the configuration is not valid.
+	ErrValidateConfig = fmt.Errorf("⛔️ command 'validate' failed")
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a grunter configuration without generating any file",
+	Long: `Validate a grunter configuration without generating any file.
+
+This command parses the JSON or YAML input file, builds its blocks and systems and
+renders the corresponding Terragrunt configurations in memory. Nothing is written
+to disk. Every problem found is reported, and the command exits with a non-zero
+status if there is at least one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Extract flag values
+		inputPath, _ := cmd.Flags().GetString("input")
+		config, err := initProject(cmd)
+		if err != nil {
+			return utils.WrapError(ErrValidateConfig, err)
+		}
+
+		inputPath, err = cmds.Validate(config, inputPath)
+		if err != nil {
+			problems := utils.ErrorList(err)
+			for _, problem := range problems {
+				fmt.Fprintf(os.Stderr, "❌ %s\n", problem)
+			}
+			return utils.WrapError(ErrValidateConfig, fmt.Errorf("%d problem(s) found", len(problems)))
+		}
+
+		fmt.Printf("✅ '%s' is valid\n", inputPath)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringP("input", "i", "", "Path to the input configuration file")
+	validateCmd.Flags().Bool("strict", false, "Reject unknown keys even for apiVersion v1 objects")
+	addTemplateFlags(validateCmd)
+	addSetFlag(validateCmd)
+}
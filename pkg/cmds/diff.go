@@ -0,0 +1,36 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for diff operations.
+var (
+	// ErrDiffConfig is returned when the pending changes cannot be computed.
+	ErrDiffConfig = fmt.Errorf("failed to compute pending changes")
+)
+
+// Diff renders the Terragrunt configuration based on the provided input and output paths
+// and compares it with the files on disk, without writing anything. If inputPath is empty,
+// it defaults to "block.yaml" or "system.yaml". It returns the files that Gen would change.
+func Diff(config Config, inputPath, outputPath string) ([]grunter.FileDiff, error) {
+	g, err := newGrunter(config, inputPath)
+	if err != nil {
+		return nil, err
+	}
+	return diff(g, outputPath, "")
+}
+
+// diff returns the pending changes of g, with the outputs compared relative to outputDir.
+func diff(g grunter.Grunter, outputPath, outputDir string) ([]grunter.FileDiff, error) {
+	// Compare the rendered files with the files on disk
+	diffs, err := g.WithOutputDir(outputDir).Diff(outputPath)
+	if err != nil {
+		return nil, utils.WrapErrors(ErrDiffConfig, err)
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,16 @@
+package cmds
+
+import (
+	"github.com/romainframe/grunter/pkg/grunter"
+)
+
+// Explain returns where every field of the blocks defined at inputPath comes from: the block
+// files they extend, the file defining them or the defaults of their system. If inputPath is
+// empty, it defaults to "block.yaml" or "system.yaml".
+func Explain(config Config, inputPath string) ([]grunter.Explanation, error) {
+	inputPath, err := config.resolveInputPath(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	return grunter.Explain(inputPath, config.Grunter)
+}
@@ -15,42 +15,60 @@ var (
 	ErrGenConfig = fmt.Errorf("failed to generate Terragrunt configuration")
 )
 
-const (
-	BlockDefaultFileName  = "block.yaml"
-	SystemDefaultFileName = "system.yaml"
-)
-
 // Gen generates the Terragrunt configuration based on the provided input and output paths.
 // If inputPath is empty, it defaults to "block.yaml". This function initializes Grunter
-// with the given inputPath, and then calls its Grunt method to generate the configuration
-// at outputPath. It handles and returns errors during the Grunter initialization and configuration
-// generation process.
-func Gen(inputPath, outputPath string) ([]string, error) {
-	// Default input path if empty
-	if inputPath == "" {
-		if utils.DoesFileOrDirExists(BlockDefaultFileName) {
-			inputPath = BlockDefaultFileName
-		} else if utils.DoesFileOrDirExists(SystemDefaultFileName) {
-			inputPath = SystemDefaultFileName
-		} else {
-			return nil, fmt.Errorf("no input path provided and no default file found")
-		}
-	}
-
-	// Initialize Grunter with the specified inputPath
-	grunter, err := grunter.New(inputPath)
+// with the given inputPath and settings, and then calls its Grunt method to generate the
+// configuration at outputPath. It handles and returns errors during the Grunter initialization
+// and configuration generation process.
+func Gen(config Config, inputPath, outputPath string) ([]string, error) {
+	g, err := newGrunter(config, inputPath)
 	if err != nil {
-		// Return an error with additional context if Grunter initialization fails
-		return nil, utils.WrapError(ErrInitGrunter, err)
+		return nil, err
 	}
+	return gen(g, outputPath, "")
+}
 
+// gen generates the Terragrunt configuration of g, with the outputs written relative to outputDir.
+func gen(g grunter.Grunter, outputPath, outputDir string) ([]string, error) {
 	// Generate the Terragrunt configuration using the initialized Grunter
-	generatedFiles, err := grunter.Gen(outputPath)
+	generatedFiles, err := g.WithOutputDir(outputDir).Gen(outputPath)
 	if err != nil {
 		// Return an error with additional context if configuration generation fails
-		return nil, utils.WrapError(ErrGenConfig, err)
+		return nil, utils.WrapErrors(ErrGenConfig, err)
 	}
 
 	// Return nil if no errors occurred, indicating success
 	return generatedFiles, nil
 }
+
+// newGrunter initializes a Grunter with the settings of config for the object at inputPath, or at
+// the default input path when it is empty (see resolveInputPath).
+func newGrunter(config Config, inputPath string) (grunter.Grunter, error) {
+	// Default input path if empty
+	inputPath, err := config.resolveInputPath(inputPath)
+	if err != nil {
+		return grunter.Grunter{}, err
+	}
+
+	// Initialize Grunter with the specified inputPath
+	g, err := grunter.NewWithConfig(inputPath, config.Grunter)
+	if err != nil {
+		// Return an error with additional context if Grunter initialization fails
+		return grunter.Grunter{}, utils.WrapErrors(ErrInitGrunter, err)
+	}
+	return g, nil
+}
+
+// resolveInputPath returns inputPath, or the first file of c.FileNames found in the
+// current directory when inputPath is empty.
+func (c Config) resolveInputPath(inputPath string) (string, error) {
+	if inputPath != "" {
+		return inputPath, nil
+	}
+	for _, name := range c.FileNames {
+		if utils.DoesFileOrDirExists(name) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no input path provided and no default file found")
+}
@@ -0,0 +1,44 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for graph operations.
+var (
+	// ErrGraphConfig is returned when the dependency graph cannot be built.
+	ErrGraphConfig = fmt.Errorf("failed to build the dependency graph")
+)
+
+// Graph returns the dependency graph of the Terragrunt configurations generated from inputPath.
+// If inputPath is empty, it defaults to "block.yaml" or "system.yaml". Nothing is written.
+func Graph(config Config, inputPath, outputPath string) (grunter.Graph, error) {
+	g, err := newGrunter(config, inputPath)
+	if err != nil {
+		return grunter.Graph{}, err
+	}
+	return graph(g, outputPath, "")
+}
+
+// GraphRecursive returns the dependency graph of every grunter object found under root, each one
+// relative to its own directory. The graphs are returned in the Result of their object so that
+// failing objects can be reported; merge the others with grunter.Graph.Merge.
+func GraphRecursive(config Config, root, outputPath string) ([]Result, error) {
+	return forEachObject(config, root, func(g grunter.Grunter, dir string) Result {
+		graph, err := graph(g, outputPath, dir)
+		return Result{Graph: graph, Err: err}
+	})
+}
+
+// graph returns the dependency graph of g, with the outputs relative to outputDir.
+func graph(g grunter.Grunter, outputPath, outputDir string) (grunter.Graph, error) {
+	graph, err := g.WithOutputDir(outputDir).Graph(outputPath)
+	if err != nil {
+		return grunter.Graph{}, utils.WrapErrors(ErrGraphConfig, err)
+	}
+
+	return graph, nil
+}
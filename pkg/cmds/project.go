@@ -0,0 +1,83 @@
+package cmds
+
+import (
+	"github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/project"
+	"github.com/romainframe/grunter/pkg/terragrunt"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Default file names, looked up when no input path is given.
+const (
+	BlockDefaultFileName    = "block.yaml"
+	SystemDefaultFileName   = "system.yaml"
+	OverlayDefaultFileName  = "overlay.yaml"
+	InstanceDefaultFileName = "instance.yaml"
+)
+
+// Config holds the settings the commands run with. Build it from a project configuration with
+// NewConfig, or start from DefaultConfig.
+type Config struct {
+	// Grunter holds the settings objects are built and rendered with.
+	Grunter grunter.Config
+	// FileNames lists the names of the grunter object files: the first one found is used when no
+	// input path is given, and all of them are recognised when scanning a tree.
+	FileNames []string
+}
+
+// DefaultConfig returns the built-in settings.
+func DefaultConfig() Config {
+	return Config{
+		Grunter:   grunter.DefaultConfig(),
+		FileNames: []string{BlockDefaultFileName, SystemDefaultFileName, OverlayDefaultFileName, InstanceDefaultFileName},
+	}
+}
+
+// NewConfig returns the settings of the commands run in the project configured by c.
+func NewConfig(c project.Config) (Config, error) {
+	config := DefaultConfig()
+	config.FileNames = []string{
+		orDefault(c.Files.Block, BlockDefaultFileName),
+		orDefault(c.Files.System, SystemDefaultFileName),
+		orDefault(c.Files.Overlay, OverlayDefaultFileName),
+		orDefault(c.Files.Instance, InstanceDefaultFileName),
+	}
+
+	locals := terragrunt.NewLocalResolvers(
+		orDefault(c.Env.Email, terragrunt.DefaultEmailEnvVar),
+		orDefault(c.Env.TemplateRoot, terragrunt.DefaultTemplateRootEnvVar),
+	)
+	for name, text := range c.SpecialLocals {
+		fn, err := terragrunt.TemplateLocal(text)
+		if err != nil {
+			return config, utils.WrapError(terragrunt.ErrInvalidSpecialLocal(name), err)
+		}
+		locals.Register(name, fn)
+	}
+	if c.DefaultLocal != "" {
+		fn, err := terragrunt.TemplateLocal(c.DefaultLocal)
+		if err != nil {
+			return config, utils.WrapError(terragrunt.ErrInvalidSpecialLocal("defaultLocal"), err)
+		}
+		locals.Default = fn
+	}
+	config.Grunter.Locals = locals
+
+	config.Grunter.Layout = c.Layout
+	config.Grunter.Templates = terragrunt.Templates{
+		Terragrunt: c.Templates.Terragrunt,
+		Values:     c.Templates.Values,
+	}
+	for name, path := range c.Catalog {
+		config.Grunter.Catalog[name] = path
+	}
+	return config, nil
+}
+
+// orDefault returns value, or def when value is empty.
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
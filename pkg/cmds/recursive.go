@@ -0,0 +1,153 @@
+package cmds
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for recursive operations.
+var (
+	// ErrRecursive is returned when at least one object failed during a recursive run.
+	ErrRecursive = func(failed, total int) error {
+		return fmt.Errorf("%d of %d object(s) failed", failed, total)
+	}
+
+	// ErrUnusedParameterValues is returned when parameters set on the command line are declared by no Blueprint.
+	ErrUnusedParameterValues = func(names []string) error {
+		return fmt.Errorf("no Blueprint declares the parameter(s) set with --set: %s", strings.Join(names, ", "))
+	}
+)
+
+// Result is the outcome of processing one grunter object found while scanning a tree.
+type Result struct {
+	InputPath      string             // Path of the object file.
+	GeneratedFiles []string           // Generated Terragrunt configurations, relative to the working directory.
+	Diffs          []grunter.FileDiff // Pending changes, in check mode.
+	Graph          grunter.Graph      // Dependency graph of the object.
+	Err            error              // Error raised while processing the object, if any.
+}
+
+// FindObjects returns the path of every grunter object file found under root, named after one of
+// config.FileNames, in a stable order. The .git and .terragrunt-cache directories and the paths
+// listed in .gitignore files are skipped.
+func FindObjects(config Config, root string) ([]string, error) {
+	var inputPaths []string
+	err := utils.WalkRepository(env.GRUNT_REPO_ROOT, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		for _, name := range config.FileNames {
+			if d.Name() == name {
+				inputPaths = append(inputPaths, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(inputPaths)
+	return inputPaths, nil
+}
+
+// GenRecursive generates the Terragrunt configuration of every grunter object found under root.
+// Each object is generated relative to its own directory, up to env.GRUNT_JOBS at a time.
+// A failing object does not stop the others; its error is reported in its Result.
+func GenRecursive(config Config, root, outputPath string) ([]Result, error) {
+	return forEachObject(config, root, func(g grunter.Grunter, dir string) Result {
+		generatedFiles, err := gen(g, outputPath, dir)
+		return Result{GeneratedFiles: generatedFiles, Err: err}
+	})
+}
+
+// DiffRecursive computes the pending changes of every grunter object found under root,
+// without writing anything.
+func DiffRecursive(config Config, root, outputPath string) ([]Result, error) {
+	return forEachObject(config, root, func(g grunter.Grunter, dir string) Result {
+		diffs, err := diff(g, outputPath, dir)
+		return Result{Diffs: diffs, Err: err}
+	})
+}
+
+// forEachObject runs fn concurrently with the Grunter of every grunter object found under root and
+// the directory of the object file, relative to the current working directory. The Result of an
+// object that cannot be initialized holds its error. Results keep the order of FindObjects.
+// Each Blueprint only takes the parameter values of config it declares, but every value must be
+// taken by at least one of them.
+func forEachObject(config Config, root string, fn func(g grunter.Grunter, dir string) Result) ([]Result, error) {
+	inputPaths, err := FindObjects(config, root)
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Grunter.IgnoreUnknownParameters = true
+	results := make([]Result, len(inputPaths))
+	used := make([][]string, len(inputPaths))
+	utils.Parallel(env.GRUNT_JOBS, len(inputPaths), func(i int) {
+		inputPath := utils.RelativeTo(cwd, inputPaths[i])
+		g, err := newGrunter(config, inputPath)
+		if err != nil {
+			results[i] = Result{Err: err}
+		} else {
+			results[i] = fn(g, filepath.Dir(inputPath))
+			used[i] = g.UsedParameters()
+		}
+		results[i].InputPath = inputPath
+	})
+
+	// An object that failed may declare the values no other object takes.
+	if FailedResults(results) > 0 {
+		return results, nil
+	}
+	if unused := unusedParameters(config.Grunter.Parameters, used); len(unused) > 0 {
+		return results, ErrUnusedParameterValues(unused)
+	}
+	return results, nil
+}
+
+// unusedParameters returns the sorted names of the parameters that appear in none of used.
+func unusedParameters(parameters map[string]string, used [][]string) []string {
+	taken := make(map[string]bool)
+	for _, names := range used {
+		for _, name := range names {
+			taken[name] = true
+		}
+	}
+	var unused []string
+	for name := range parameters {
+		if !taken[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// FailedResults returns the number of results holding an error.
+func FailedResults(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
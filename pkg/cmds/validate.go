@@ -0,0 +1,38 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/romainframe/grunter/pkg/grunter"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Predefined errors for validation.
+var (
+	// ErrInvalidConfig is returned when the configuration is not valid.
+	ErrInvalidConfig = fmt.Errorf("invalid configuration")
+)
+
+// Validate parses, builds and renders the configuration found at inputPath without writing
+// any file. If inputPath is empty, it defaults to "block.yaml" or "system.yaml". It returns the
+// resolved input path and every problem found, wrapped with ErrInvalidConfig.
+func Validate(config Config, inputPath string) (string, error) {
+	// Default input path if empty
+	inputPath, err := config.resolveInputPath(inputPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Initialize Grunter with the specified inputPath, which parses and builds the object.
+	grunter, err := grunter.NewWithConfig(inputPath, config.Grunter)
+	if err != nil {
+		return inputPath, utils.WrapErrors(ErrInvalidConfig, err)
+	}
+
+	// Convert and render the Terragrunt configurations in memory.
+	if err := grunter.Validate(""); err != nil {
+		return inputPath, utils.WrapErrors(ErrInvalidConfig, err)
+	}
+
+	return inputPath, nil
+}
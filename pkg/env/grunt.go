@@ -1,5 +1,11 @@
 package env
 
+import "runtime"
+
 var (
 	GRUNT_REPO_ROOT = ""
+	// GRUNT_STRICT forces strict decoding, which rejects unknown keys, whatever the apiVersion.
+	GRUNT_STRICT = false
+	// GRUNT_JOBS is the maximum number of blocks, files or objects processed concurrently.
+	GRUNT_JOBS = runtime.NumCPU()
 )
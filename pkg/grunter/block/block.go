@@ -6,19 +6,54 @@ import (
 	"os"
 	"path/filepath"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/romainframe/grunter/pkg/terragrunt"
 )
 
 // Block holds the structure for application configuration, supporting nested objects
 // for various configuration aspects like metadata, dependencies, and hooks.
 type Block struct {
-	Name         string            `json:"name"`         // Unique identifier for the block.
-	Template     string            `json:"template"`     // Template path or identifier.
-	Metadata     map[string]string `json:"metadata"`     // Arbitrary metadata for templating.
-	Dependencies []Dependency      `json:"dependencies"` // List of external dependencies.
-	Locals       map[string]string `json:"locals"`       // Local variables for templating.
-	Inputs       map[string]string `json:"inputs"`       // Input variables for customization.
-	BeforeHooks  []BeforeHook      `json:"beforeHooks"`  // Hooks to run before execution.
+	Name         string                 `json:"name"`         // Unique identifier for the block.
+	Extends      string                 `json:"extends"`      // Block file or catalog entry the block is merged over, resolved before decoding.
+	Template     string                 `json:"template"`     // Template path or identifier.
+	Metadata     map[string]string      `json:"metadata"`     // Arbitrary metadata for templating.
+	Dependencies []Dependency           `json:"dependencies"` // List of external dependencies.
+	Locals       map[string]string      `json:"locals"`       // Local variables for templating.
+	Inputs       map[string]interface{} `json:"inputs"`       // Input variables: strings, numbers, booleans, lists and maps.
+	BeforeHooks  []BeforeHook           `json:"beforeHooks"`  // Hooks to run before execution.
+	// Templates overrides the output templates for this block. Paths are relative to the block file.
+	Templates terragrunt.Templates `json:"templates"`
+	// Matrix fans the block out into one block per combination of the values of its axes, such as
+	// env: [dev, prod]. Cells overrides the metadata and inputs of some of them. See Expand.
+	Matrix map[string][]string `json:"matrix"`
+	Cells  []CellOverride      `json:"cells"`
+
+	// Cell is the path of the matrix cell of the block, such as prod/europe-west1, and Axes the
+	// sorted axes of its matrix. They are set by Expand, not decoded.
+	Cell string   `json:"-" mapstructure:"-"`
+	Axes []string `json:"-" mapstructure:"-"`
+
+	// Dir is the directory the block is generated from. Relative paths and upward
+	// searches are resolved from it. It is set by the caller, not decoded.
+	Dir string `json:"-" mapstructure:"-"`
+	// OutputDir is the directory the block's terragrunt.hcl is written to. Dependency paths are
+	// computed and checked from it. It is set by the caller, not decoded.
+	OutputDir string `json:"-" mapstructure:"-"`
+	// Targets maps the path in the system tree of every block generated along with this one
+	// (see TargetName) to its output directory. Block dependencies are resolved against it.
+	// It is set by the caller, not decoded.
+	Targets map[string]string `json:"-" mapstructure:"-"`
+	// Matrices maps the path in the system tree of every block of a matrix generated along with
+	// this one (see TargetName) to the paths of its cells, in the order of Expand. It is set by the caller, not decoded.
+	Matrices map[string][]string `json:"-" mapstructure:"-"`
+	// System is the path in the system tree of the system holding the block, such as
+	// platform/network. Relative block references start from it. It is set by the caller, not decoded.
+	System string `json:"-" mapstructure:"-"`
+	// StrictInputs restricts the plain string inputs resolved as references to the dotted paths
+	// starting with values., local. or dependency. (see processInputString). It is set by the
+	// caller, not decoded.
+	StrictInputs bool `json:"-" mapstructure:"-"`
 }
 
 // BeforeHook defines a pre-execution hook with a name, commands to run, and
@@ -33,12 +68,21 @@ type BeforeHook struct {
 // Dependency describes an external dependency with its source, path, and type.
 // It includes an option to fetch outputs from the dependency, if applicable.
 type Dependency struct {
-	Name        string `json:"name"`        // Name of the dependency.
+	Name        string `json:"name"`        // Name of the dependency. Defaults to the name of the referenced block.
+	Block       string `json:"block"`       // Block of the same system tree, such as cluster, ../network/vpc or db@prod.
 	Path        string `json:"path"`        // Location or path to the dependency.
-	PathType    string `json:"pathType"`    // Type of the path (e.g., local, remote).
+	PathType    string `json:"pathType"`    // Type of the path: relative, root, absolute or block (see resolveDependencyPath).
 	WithOutputs bool   `json:"withOutputs"` // Whether to include outputs from the dependency.
 }
 
+// Dependency path types.
+const (
+	PathTypeRelative = "relative" // Relative to the output directory of the block.
+	PathTypeRoot     = "root"     // Relative to the repository root, rendered with get_repo_root().
+	PathTypeAbsolute = "absolute" // Absolute path.
+	PathTypeBlock    = "block"    // Path in the system tree of another block generated along with this one.
+)
+
 // NewFromFile creates a Block object from a JSON or YAML file located at configPath.
 // It reads the file, unmarshals into a Block struct, and processes
 // it through Build() to build & validate the config.
@@ -25,16 +25,23 @@ var builders = []GruntBuilder{
 // Build iterates over all registered builders, applying those that match the current configuration.
 // It ensures that the configuration meets all requirements before attempting to build.
 func (b Block) Build(systemName string, extraBuilders ...GruntBuilder) (Block, error) {
-	// Name is required for building the config; return an error if it's missing.
+	// Name and template are required for building the config; report both if they're missing.
+	var errs utils.Errors
 	if b.Name == "" {
-		return b, ErrNameRequired
+		errs = errs.Append(utils.AtPath("name", ErrNameRequired))
 	}
-	b.Name = normalizeName(fmt.Sprintf("%s/%s", systemName, b.Name))
-
-	// Template is required for building the config; return an error if it's missing.
 	if b.Template == "" {
-		return b, ErrTemplateRequired
+		errs = errs.Append(utils.AtPath("template", ErrTemplateRequired))
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return b, err
 	}
+	// Interpolate the values of the block with its metadata before any builder reads them.
+	b, err := b.interpolate(InterpolationData{Name: b.Name, System: systemName, Cell: b.Cell, Metadata: b.Metadata})
+	if err != nil {
+		return b, err
+	}
+	b.Name = normalizeName(fmt.Sprintf("%s/%s", systemName, b.Name))
 
 	// Initialize Locals map if not already done. This avoids nil map assignments.
 	if b.Locals == nil {
@@ -17,61 +17,86 @@ var K8sBeforeHooks = map[string]BeforeHook{
 	},
 }
 
-// K8sGruntBuilder initializes a GruntBuilder specifically for Kubernetes templates.
+// K8sConfig holds the settings of the Kubernetes builder.
+type K8sConfig struct {
+	ParentFolder string                // Folder, searched upwards from the block, holding one folder per cluster.
+	ClusterFile  string                // File read from the cluster folder into the "cluster" local.
+	BeforeHooks  map[string]BeforeHook // Hooks added to Kubernetes blocks, by cloud.
+}
+
+// DefaultK8sConfig returns the built-in settings of the Kubernetes builder, with a copy of K8sBeforeHooks.
+func DefaultK8sConfig() K8sConfig {
+	beforeHooks := make(map[string]BeforeHook, len(K8sBeforeHooks))
+	for cloud, hook := range K8sBeforeHooks {
+		beforeHooks[cloud] = hook
+	}
+	return K8sConfig{
+		ParentFolder: "services/k8s",
+		ClusterFile:  "values.hcl",
+		BeforeHooks:  beforeHooks,
+	}
+}
+
+// K8sGruntBuilder is the Kubernetes builder with the built-in settings (see NewK8sGruntBuilder).
+var K8sGruntBuilder = NewK8sGruntBuilder(DefaultK8sConfig())
+
+// NewK8sGruntBuilder initializes a GruntBuilder specifically for Kubernetes templates.
 // It ensures the presence of required metadata and sets up necessary local configurations
-// and pre-execution hooks based on the cloud environment.
-var K8sGruntBuilder = GruntBuilder{
-	// Matches returns true if the configuration template is intended for Kubernetes.
-	Matches: func(c Block) bool {
-		return strings.Contains(c.Template, "k8s")
-	},
-	// Build enriches the provided Block with Kubernetes-specific settings.
-	// It validates the template, metadata, and cluster configuration, sets the cluster local,
-	// and adds the appropriate pre-execution hook based on the cloud environment.
-	Build: func(c Block) (Block, error) {
-		if c.Template == "" {
-			return c, ErrTemplateRequired
-		}
-		if len(c.Metadata) == 0 {
-			return c, ErrMetadataRequired
-		}
+// and pre-execution hooks based on the cloud environment, as config tells.
+func NewK8sGruntBuilder(config K8sConfig) GruntBuilder {
+	return GruntBuilder{
+		// Matches returns true if the configuration template is intended for Kubernetes.
+		Matches: func(c Block) bool {
+			return strings.Contains(c.Template, "k8s")
+		},
+		// Build enriches the provided Block with Kubernetes-specific settings.
+		// It validates the template, metadata, and cluster configuration, sets the cluster local,
+		// and adds the appropriate pre-execution hook based on the cloud environment.
+		Build: func(c Block) (Block, error) {
+			if c.Template == "" {
+				return c, utils.AtPath("template", ErrTemplateRequired)
+			}
+			if len(c.Metadata) == 0 {
+				return c, utils.AtPath("metadata", ErrMetadataRequired)
+			}
 
-		// Validate and retrieve cluster metadata
-		clusterMetadataValue, ok := c.Metadata["cluster"]
-		if !ok || clusterMetadataValue == "" {
-			return c, ErrMetadataKeyRequired("cluster")
-		}
+			// Validate and retrieve cluster metadata
+			clusterMetadataValue, ok := c.Metadata["cluster"]
+			if !ok || clusterMetadataValue == "" {
+				return c, utils.AtPath("metadata.cluster", ErrMetadataKeyRequired("cluster"))
+			}
 
-		// Find and set the cluster local configuration
-		parentFolder := "services/k8s"
-		clusterFile := "values.hcl"
-		clusterFilePath, err := utils.FindFileInParentTarget(parentFolder, clusterMetadataValue, clusterFile, 50)
-		if err != nil {
-			return c, utils.WrapError(ErrFilePathNotFound(fmt.Sprintf("%s/%s/.../%s", parentFolder, clusterMetadataValue, clusterFile)), err)
-		}
-		c.Locals["cluster"] = fmt.Sprintf(`read_terragrunt_config("%s")`, clusterFilePath)
+			// Find and set the cluster local configuration
+			parentFolder := config.ParentFolder
+			clusterFile := config.ClusterFile
+			clusterFilePath, err := utils.FindFileInParentTarget(c.Dir, parentFolder, clusterMetadataValue, clusterFile, 50)
+			if err != nil {
+				return c, utils.AtPath("metadata.cluster", utils.WrapError(ErrFilePathNotFound(fmt.Sprintf("%s/%s/.../%s", parentFolder, clusterMetadataValue, clusterFile)), err))
+			}
+			c.Locals["cluster"] = fmt.Sprintf(`read_terragrunt_config("%s")`, clusterFilePath)
 
-		// Determine the cloud environment type
-		hcl, err := utils.GetHCLFromParent("cloud")
-		if err != nil {
-			return c, utils.WrapError(ErrInvalidFile("cloud.hcl"), err)
-		}
-		cloudType, ok := utils.Get(hcl, "locals.slug")
-		if !ok || cloudType == "" {
-			return c, utils.WrapError(ErrKeyRequired("cloud.locals.slug"), err)
-		}
+			// Determine the cloud environment type
+			hcl, err := utils.GetHCLFromParent(c.Dir, "cloud")
+			if err != nil {
+				return c, utils.WrapError(ErrInvalidFile("cloud.hcl"), err)
+			}
+			cloudType, ok := utils.Get(hcl, "locals.slug")
+			if !ok || cloudType == "" {
+				return c, utils.WrapError(ErrKeyRequired("cloud.locals.slug"), err)
+			}
 
-		// Add the appropriate pre-execution hook if it hasn't been added yet
-		if !hasBeforeHook(c.BeforeHooks, fmt.Sprintf("%s-context", cloudType)) {
-			k8sBeforeHook, ok := K8sBeforeHooks[cloudType]
-			if !ok {
-				return c, ErrBeforeHookNotFound(cloudType)
+			// Add the appropriate pre-execution hook if it hasn't been added yet
+			if !hasBeforeHook(c.BeforeHooks, fmt.Sprintf("%s-context", cloudType)) {
+				k8sBeforeHook, ok := config.BeforeHooks[cloudType]
+				if !ok {
+					return c, ErrBeforeHookNotFound(cloudType)
+				}
+				c.BeforeHooks = append(c.BeforeHooks, k8sBeforeHook)
 			}
-			c.BeforeHooks = append(c.BeforeHooks, k8sBeforeHook)
-		}
 
-		return c, nil
-	},
+			return c, nil
+		},
+	}
 }
 
 // hasBeforeHook checks if the specified hook name is already present in the given slice of BeforeHook.
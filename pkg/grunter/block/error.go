@@ -1,6 +1,9 @@
 package block
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Predefined errors for file operations.
 var (
@@ -65,4 +68,94 @@ var (
 	ErrProcessDependencies = func(path string) error {
 		return fmt.Errorf("failed to process dependency with path '%s'", path)
 	}
+
+	// ErrProcessBlockDependency is returned when processing a dependency on a block fails.
+	ErrProcessBlockDependency = func(ref string) error {
+		return fmt.Errorf("failed to process dependency on block '%s'", ref)
+	}
+
+	// ErrInvalidReference is returned when a $ref input is not a reference.
+	ErrInvalidReference = func(value string) error {
+		return fmt.Errorf("'%s' is not a valid reference, expected a dotted path such as 'values.name'", value)
+	}
+
+	// ErrInvalidExpression is returned when an $expr input is not a valid HCL expression.
+	ErrInvalidExpression = func(value string) error {
+		return fmt.Errorf("'%s' is not a valid HCL expression", value)
+	}
+
+	// ErrInvalidExplicitInput is returned when an explicit input form does not hold a string.
+	ErrInvalidExplicitInput = func(form string) error {
+		return fmt.Errorf("'%s' must hold a string", form)
+	}
+
+	// ErrUnknownPathType is returned when a dependency has an unsupported path type.
+	ErrUnknownPathType = func(pathType string) error {
+		return fmt.Errorf("unknown path type '%s', expected one of: relative, root, absolute, block", pathType)
+	}
+
+	// ErrDependencyNotFound is returned when the target of a dependency does not exist.
+	ErrDependencyNotFound = func(pathType, path, location string) error {
+		return fmt.Errorf("%s dependency '%s' not found at '%s'", pathType, path, location)
+	}
+
+	// ErrDuplicateDependency is returned when two dependencies of a block have the same name.
+	ErrDuplicateDependency = func(name string, first int) error {
+		return fmt.Errorf("dependency '%s' is already declared by dependencies[%d], set a distinct name", name, first)
+	}
+
+	// ErrDependencyBlockNotFound is returned when a dependency names a block that is not generated.
+	ErrDependencyBlockNotFound = func(name string) error {
+		return fmt.Errorf("block dependency '%s' not found among the generated blocks", name)
+	}
+
+	// ErrMatrixBlockReference is returned when a dependency names a block of a matrix without a cell.
+	ErrMatrixBlockReference = func(ref string, cells []string) error {
+		refs := make([]string, len(cells))
+		for i, cell := range cells {
+			refs[i] = ref + "@" + cell
+		}
+		return fmt.Errorf("block dependency '%s' is a matrix block, designate one of its cells: %s", ref, strings.Join(refs, ", "))
+	}
+
+	// ErrInvalidBlockReference is returned when a block reference goes above the root of the system tree.
+	ErrInvalidBlockReference = func(ref string) error {
+		return fmt.Errorf("invalid block reference '%s': outside of the system tree", ref)
+	}
+
+	// ErrDependencyPathRequired is returned when a dependency has neither a block nor a path.
+	ErrDependencyPathRequired = fmt.Errorf("path is required")
+
+	// ErrInvalidDependencyPath is returned when a dependency path does not match its path type.
+	ErrInvalidDependencyPath = func(pathType, path, reason string) error {
+		return fmt.Errorf("invalid %s dependency path '%s': %s", pathType, path, reason)
+	}
+
+	// ErrEmptyMatrixAxis is returned when an axis of a matrix has no value.
+	ErrEmptyMatrixAxis = func(axis string) error {
+		return fmt.Errorf("matrix axis '%s' has no value", axis)
+	}
+
+	// ErrUnknownMatrixValue is returned when a cell override matches a value that is not in the matrix.
+	ErrUnknownMatrixValue = func(axis, value string) error {
+		return fmt.Errorf("'%s' is not a value of the matrix axis '%s'", value, axis)
+	}
+
+	// ErrInvalidMatrixValue is returned when a value of a matrix axis cannot name the directory of a cell.
+	ErrInvalidMatrixValue = func(value string) error {
+		return fmt.Errorf("invalid matrix value '%s', it must not be empty, '.' or '..', nor hold '/', '\\' or '@'", value)
+	}
+
+	// ErrCellsWithoutMatrix is returned when a block overrides cells without declaring a matrix.
+	ErrCellsWithoutMatrix = fmt.Errorf("cells require a matrix")
+
+	// ErrInterpolate is returned when a value of a block cannot be interpolated.
+	ErrInterpolate = func(value string) error {
+		return fmt.Errorf("could not interpolate '%s'", value)
+	}
+
+	// ErrEnvNotSet is returned when an interpolated value reads an environment variable that is not set.
+	ErrEnvNotSet = func(name string) error {
+		return fmt.Errorf("environment variable '%s' is not set", name)
+	}
 )
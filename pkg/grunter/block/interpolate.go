@@ -0,0 +1,162 @@
+package block
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// InterpolationData is the data the values of a block are interpolated with, such as
+// {{ .Metadata.cluster }} or {{ .Name }}-{{ .Cell }}.
+type InterpolationData struct {
+	Name     string            // Name of the block, as written.
+	System   string            // Name of the system holding the block, empty for a Block object.
+	Cell     string            // Path of the matrix cell of the block, empty without matrix.
+	Metadata map[string]string // Metadata of the block.
+}
+
+// interpolationFuncs are the functions available to interpolated values, besides the built-in ones.
+var interpolationFuncs = template.FuncMap{
+	// env returns the value of an environment variable, which must be set.
+	"env": func(name string) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", ErrEnvNotSet(name)
+		}
+		return value, nil
+	},
+	// envOr returns the value of an environment variable, or fallback when it is not set.
+	"envOr": func(name, fallback string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return fallback
+	},
+}
+
+// interpolate returns the block with the Go templates held by its template, inputs, locals,
+// dependencies and hooks executed with data. Strings without {{ are left as is. Referencing
+// a metadata the block does not set is an error. Every problem found is reported.
+func (b Block) interpolate(data InterpolationData) (Block, error) {
+	var errs utils.Errors
+	str := func(field, value string) string {
+		result, err := interpolateString(value, data)
+		errs = errs.Append(utils.AtPath(field, err))
+		return result
+	}
+	strs := func(field string, values []string) []string {
+		if values == nil {
+			return nil
+		}
+		result := make([]string, len(values))
+		for i, value := range values {
+			result[i] = str(fmt.Sprintf("%s[%d]", field, i), value)
+		}
+		return result
+	}
+
+	b.Template = str("template", b.Template)
+
+	if b.Locals != nil {
+		names := make([]string, 0, len(b.Locals))
+		for name := range b.Locals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		locals := make(map[string]string, len(b.Locals))
+		for _, name := range names {
+			locals[name] = str(utils.JoinPath("locals", name), b.Locals[name])
+		}
+		b.Locals = locals
+	}
+
+	if b.Inputs != nil {
+		inputs := make(map[string]interface{}, len(b.Inputs))
+		for _, name := range sortedKeys(b.Inputs) {
+			value, err := interpolateValue(utils.JoinPath("inputs", name), b.Inputs[name], data)
+			errs = errs.Append(err)
+			inputs[name] = value
+		}
+		b.Inputs = inputs
+	}
+
+	if b.Dependencies != nil {
+		dependencies := make([]Dependency, len(b.Dependencies))
+		for i, dep := range b.Dependencies {
+			field := fmt.Sprintf("dependencies[%d]", i)
+			dep.Name = str(field+".name", dep.Name)
+			dep.Block = str(field+".block", dep.Block)
+			dep.Path = str(field+".path", dep.Path)
+			dependencies[i] = dep
+		}
+		b.Dependencies = dependencies
+	}
+
+	if b.BeforeHooks != nil {
+		hooks := make([]BeforeHook, len(b.BeforeHooks))
+		for i, hook := range b.BeforeHooks {
+			field := fmt.Sprintf("beforeHooks[%d]", i)
+			hook.Name = str(field+".name", hook.Name)
+			hook.Commands = strs(field+".commands", hook.Commands)
+			hook.Execute = strs(field+".execute", hook.Execute)
+			hooks[i] = hook
+		}
+		b.BeforeHooks = hooks
+	}
+
+	return b, errs.ErrorOrNil()
+}
+
+// interpolateValue interpolates every string held by an input value, found at field. Explicit
+// inputs, such as {$literal: ...}, are kept as written, so that they can pass templates of other
+// tools through.
+func interpolateValue(field string, value interface{}, data InterpolationData) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		result, err := interpolateString(v, data)
+		return result, utils.AtPath(field, err)
+	case []interface{}:
+		var errs utils.Errors
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			result[i], err = interpolateValue(fmt.Sprintf("%s[%d]", field, i), item, data)
+			errs = errs.Append(err)
+		}
+		return result, errs.ErrorOrNil()
+	case map[string]interface{}:
+		if _, ok := ExplicitInputForm(v); ok {
+			return value, nil
+		}
+		var errs utils.Errors
+		result := make(map[string]interface{}, len(v))
+		for _, key := range sortedKeys(v) {
+			var err error
+			result[key], err = interpolateValue(utils.JoinPath(field, key), v[key], data)
+			errs = errs.Append(err)
+		}
+		return result, errs.ErrorOrNil()
+	default:
+		return value, nil
+	}
+}
+
+// interpolateString executes value as a Go template with data, unless it holds no {{.
+func interpolateString(value string, data InterpolationData) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tmpl, err := template.New("value").Option("missingkey=error").Funcs(interpolationFuncs).Parse(value)
+	if err != nil {
+		return value, utils.WrapError(ErrInterpolate(value), err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return value, utils.WrapError(ErrInterpolate(value), err)
+	}
+	return out.String(), nil
+}
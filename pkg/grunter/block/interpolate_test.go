@@ -0,0 +1,47 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInterpolateValue(t *testing.T) {
+	data := InterpolationData{
+		Name:     "app",
+		System:   "platform",
+		Cell:     "prod",
+		Metadata: map[string]string{"env": "prod"},
+	}
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "without template", value: "plain ${local.a}", want: "plain ${local.a}"},
+		{name: "name and metadata", value: "{{ .Name }}-{{ .Metadata.env }}", want: "app-prod"},
+		{name: "system and cell", value: "{{ .System }}/{{ .Cell }}", want: "platform/prod"},
+		{name: "escaped braces", value: `Deployed by {{"{{"}} .Author }}`, want: "Deployed by {{ .Author }}"},
+		{name: "not a string", value: 3, want: 3},
+		{name: "list", value: []interface{}{"{{ .Name }}", true}, want: []interface{}{"app", true}},
+		{name: "map", value: map[string]interface{}{"owner": "{{ .Metadata.env }}"}, want: map[string]interface{}{"owner": "prod"}},
+		{name: "explicit literal kept", value: map[string]interface{}{InputLiteral: "High CPU on {{ $labels.instance }}"}, want: map[string]interface{}{InputLiteral: "High CPU on {{ $labels.instance }}"}},
+		{name: "explicit expression kept", value: map[string]interface{}{InputExpression: `"{{ .Name }}"`}, want: map[string]interface{}{InputExpression: `"{{ .Name }}"`}},
+		{name: "unknown metadata", value: "{{ .Metadata.team }}", wantErr: true},
+		{name: "invalid template", value: "{{ .Name", wantErr: true},
+		{name: "unset environment variable", value: `{{ env "GRUNTER_TEST_UNSET" }}`, wantErr: true},
+		{name: "environment variable fallback", value: `{{ envOr "GRUNTER_TEST_UNSET" "platform" }}`, want: "platform"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolateValue("inputs.value", tt.value, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolateValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("interpolateValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package block
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// CellOverride overrides the metadata and inputs of the cells of a matrix matching When.
+type CellOverride struct {
+	When     map[string]string      `json:"when"`     // Value of some axes of the matrix, such as env: prod.
+	Metadata map[string]string      `json:"metadata"` // Metadata merged over the one of the cells.
+	Inputs   map[string]interface{} `json:"inputs"`   // Inputs merged over the ones of the cells, like MergeSpec does.
+}
+
+// Expand returns one block per cell of the matrix of the block, that is per combination of the
+// values of its axes, or the block itself when it has no matrix. The value of every axis is added
+// to the metadata of the cell, then the overrides matching the cell are applied, in order.
+// The path of each cell is the values of its axes, sorted by axis name, such as prod/europe-west1.
+func (b Block) Expand() ([]Block, error) {
+	if len(b.Matrix) == 0 {
+		if len(b.Cells) > 0 {
+			return nil, utils.AtPath("cells", ErrCellsWithoutMatrix)
+		}
+		return []Block{b}, nil
+	}
+
+	axes := make([]string, 0, len(b.Matrix))
+	for axis := range b.Matrix {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+
+	var errs utils.Errors
+	for _, axis := range axes {
+		if len(b.Matrix[axis]) == 0 {
+			errs = errs.Append(utils.AtPath(utils.JoinPath("matrix", axis), ErrEmptyMatrixAxis(axis)))
+		}
+		for i, value := range b.Matrix[axis] {
+			if !validCellValue(value) {
+				errs = errs.Append(utils.AtPath(fmt.Sprintf("%s[%d]", utils.JoinPath("matrix", axis), i), ErrInvalidMatrixValue(value)))
+			}
+		}
+	}
+	for i, override := range b.Cells {
+		for axis, value := range override.When {
+			if !contains(b.Matrix[axis], value) {
+				errs = errs.Append(utils.AtPath(fmt.Sprintf("cells[%d].when.%s", i, axis), ErrUnknownMatrixValue(axis, value)))
+			}
+		}
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	cells := []map[string]string{{}}
+	for _, axis := range axes {
+		var next []map[string]string
+		for _, cell := range cells {
+			for _, value := range b.Matrix[axis] {
+				c := make(map[string]string, len(cell)+1)
+				for k, v := range cell {
+					c[k] = v
+				}
+				c[axis] = value
+				next = append(next, c)
+			}
+		}
+		cells = next
+	}
+
+	blocks := make([]Block, 0, len(cells))
+	for _, values := range cells {
+		blocks = append(blocks, b.cell(axes, values))
+	}
+	return blocks, nil
+}
+
+// validCellValue reports whether value can be a segment of the path of a cell: a single directory,
+// which cannot be mistaken for a cell reference (see resolveBlockReference).
+func validCellValue(value string) bool {
+	return value != "" && value != "." && value != ".." && !strings.ContainsAny(value, `/\@`)
+}
+
+// cell returns the block of the matrix cell with the given axis values. Maps and lists are copied,
+// so that cells can be built concurrently.
+func (b Block) cell(axes []string, values map[string]string) Block {
+	segments := make([]string, 0, len(axes))
+	for _, axis := range axes {
+		segments = append(segments, values[axis])
+	}
+
+	c := b
+	c.Matrix = nil
+	c.Cells = nil
+	c.Cell = path.Join(segments...)
+	c.Axes = axes
+	c.Dependencies = append([]Dependency(nil), b.Dependencies...)
+	c.BeforeHooks = append([]BeforeHook(nil), b.BeforeHooks...)
+
+	c.Locals = make(map[string]string, len(b.Locals))
+	for k, v := range b.Locals {
+		c.Locals[k] = v
+	}
+	c.Metadata = make(map[string]string, len(b.Metadata)+len(values))
+	for k, v := range b.Metadata {
+		c.Metadata[k] = v
+	}
+	for axis, value := range values {
+		c.Metadata[axis] = value
+	}
+	inputs, _ := utils.DeepCopy(b.Inputs).(map[string]interface{})
+	c.Inputs = inputs
+
+	for _, override := range b.Cells {
+		if !matches(override.When, values) {
+			continue
+		}
+		for k, v := range override.Metadata {
+			c.Metadata[k] = v
+		}
+		if override.Inputs != nil {
+			merged, _ := mergeMaps(c.Inputs, override.Inputs).(map[string]interface{})
+			c.Inputs = merged
+		}
+	}
+	return c
+}
+
+// matches reports whether the cell with the given axis values matches every value of when.
+func matches(when, values map[string]string) bool {
+	for axis, value := range when {
+		if values[axis] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	type cell struct {
+		Cell     string
+		Metadata map[string]string
+		Inputs   map[string]interface{}
+	}
+	tests := []struct {
+		name    string
+		block   Block
+		want    []cell
+		wantErr bool
+	}{
+		{
+			name:  "without matrix",
+			block: Block{Name: "app", Metadata: map[string]string{"team": "a"}},
+			want:  []cell{{Metadata: map[string]string{"team": "a"}}},
+		},
+		{
+			name:  "axes sorted by name",
+			block: Block{Name: "app", Matrix: map[string][]string{"region": {"eu", "us"}, "env": {"dev", "prod"}}},
+			want: []cell{
+				{Cell: "dev/eu", Metadata: map[string]string{"env": "dev", "region": "eu"}},
+				{Cell: "dev/us", Metadata: map[string]string{"env": "dev", "region": "us"}},
+				{Cell: "prod/eu", Metadata: map[string]string{"env": "prod", "region": "eu"}},
+				{Cell: "prod/us", Metadata: map[string]string{"env": "prod", "region": "us"}},
+			},
+		},
+		{
+			name: "overrides applied in order",
+			block: Block{
+				Name:   "app",
+				Inputs: map[string]interface{}{"size": 1, "labels": map[string]interface{}{"tier": "web"}},
+				Matrix: map[string][]string{"env": {"dev", "prod"}},
+				Cells: []CellOverride{
+					{When: map[string]string{"env": "prod"}, Metadata: map[string]string{"critical": "true"}, Inputs: map[string]interface{}{"size": 3}},
+					{When: map[string]string{"env": "prod"}, Inputs: map[string]interface{}{"labels": map[string]interface{}{"pager": "on"}}},
+				},
+			},
+			want: []cell{
+				{Cell: "dev", Metadata: map[string]string{"env": "dev"}, Inputs: map[string]interface{}{"size": 1, "labels": map[string]interface{}{"tier": "web"}}},
+				{Cell: "prod", Metadata: map[string]string{"env": "prod", "critical": "true"}, Inputs: map[string]interface{}{"size": 3, "labels": map[string]interface{}{"tier": "web", "pager": "on"}}},
+			},
+		},
+		{
+			name:    "empty axis",
+			block:   Block{Name: "app", Matrix: map[string][]string{"env": {}}},
+			wantErr: true,
+		},
+		{
+			name:    "value naming no directory",
+			block:   Block{Name: "app", Matrix: map[string][]string{"env": {"prod/eu"}}},
+			wantErr: true,
+		},
+		{
+			name:    "value mistaken for a cell reference",
+			block:   Block{Name: "app", Matrix: map[string][]string{"env": {"prod@eu"}}},
+			wantErr: true,
+		},
+		{
+			name:    "override of an unknown value",
+			block:   Block{Name: "app", Matrix: map[string][]string{"env": {"dev"}}, Cells: []CellOverride{{When: map[string]string{"env": "prod"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "cells without matrix",
+			block:   Block{Name: "app", Cells: []CellOverride{{When: map[string]string{"env": "prod"}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := tt.block.Expand()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([]cell, len(blocks))
+			for i, b := range blocks {
+				got[i] = cell{Cell: b.Cell, Metadata: b.Metadata}
+				if len(b.Inputs) > 0 {
+					got[i].Inputs = b.Inputs
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
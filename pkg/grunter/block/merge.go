@@ -0,0 +1,167 @@
+package block
+
+import (
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// UnsetKey removes an inherited hook or dependency when set to true on an entry of the
+// same name, such as {name: network, $unset: true}.
+const UnsetKey = "$unset"
+
+// Keys of the block fields merged entry by entry, as written in configuration files.
+const (
+	beforeHooksKey  = "beforeHooks"
+	dependenciesKey = "dependencies"
+)
+
+// MergeSpec merges the fields of a block spec over the ones of base, both as produced by a YAML or
+// JSON unmarshaller, and returns the result. Values set in values win:
+//   - a field or a map key set to null is unset;
+//   - maps, such as metadata, locals and inputs, are merged key by key and recursively, except
+//     explicit inputs, which are replaced;
+//   - beforeHooks and dependencies are merged by name: an entry replaces the one of the same name,
+//     unless it sets $unset, which removes it, and the other entries are appended. Dependencies
+//     without a name are identified by their block, then by their path;
+//   - any other value replaces the one of base.
+//
+// Values of an unexpected type are left to the decoder to report.
+func MergeSpec(base, values map[string]interface{}) map[string]interface{} {
+	merged := utils.CopyMap(base)
+	for key, value := range values {
+		baseKey, baseValue, ok := utils.LookupKey(base, key)
+		if ok && baseKey != key {
+			delete(merged, baseKey)
+		}
+		switch {
+		case value == nil:
+			delete(merged, key)
+		case strings.EqualFold(key, beforeHooksKey):
+			merged[key] = MergeList(baseValue, value, hookID, replace)
+		case strings.EqualFold(key, dependenciesKey):
+			merged[key] = MergeList(baseValue, value, dependencyID, replace)
+		case !ok || baseValue == nil:
+			merged[key] = value
+		default:
+			merged[key] = mergeMaps(baseValue, value)
+		}
+	}
+	return merged
+}
+
+// mergeMaps merges value over baseValue when both are maps, recursively, except explicit inputs,
+// which are replaced. Keys set to null are removed. Any other value replaces baseValue.
+func mergeMaps(baseValue, value interface{}) interface{} {
+	base, ok := baseValue.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	if _, ok := ExplicitInputForm(base); ok {
+		return value
+	}
+	if _, ok := ExplicitInputForm(values); ok {
+		return value
+	}
+
+	merged := utils.CopyMap(base)
+	for key, v := range values {
+		if v == nil {
+			delete(merged, key)
+			continue
+		}
+		if b, ok := merged[key]; ok {
+			merged[key] = mergeMaps(b, v)
+		} else {
+			merged[key] = v
+		}
+	}
+	return merged
+}
+
+// MergeList merges the entries of a list over the ones of baseValue, matching entries with id. An
+// entry is merged over the one of the same identifier with merge, in place; an entry setting $unset
+// removes it. Other entries are appended, in order. A nil baseValue is an empty list, so that entries
+// setting $unset are always dropped. Values of an unexpected type replace baseValue.
+func MergeList(baseValue, value interface{}, id func(map[string]interface{}) string, merge func(base, values map[string]interface{}) map[string]interface{}) interface{} {
+	values, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+	base, ok := baseValue.([]interface{})
+	if !ok && baseValue != nil {
+		return value
+	}
+
+	merged := append([]interface{}{}, base...)
+	for _, v := range values {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			merged = append(merged, v)
+			continue
+		}
+		unset := false
+		if key, u, ok := utils.LookupKey(entry, UnsetKey); ok {
+			unset = u == true
+			entry = utils.CopyMap(entry)
+			delete(entry, key)
+		}
+
+		index := indexOf(merged, id(entry), id)
+		switch {
+		case unset && index >= 0:
+			merged = append(merged[:index], merged[index+1:]...)
+		case unset:
+			// Nothing to unset.
+		case index >= 0:
+			merged[index] = merge(merged[index].(map[string]interface{}), entry)
+		default:
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// replace is the merge of MergeList replacing the entry of base with the new one.
+func replace(_, values map[string]interface{}) map[string]interface{} {
+	return values
+}
+
+// indexOf returns the index of the entry of list identified by entryID, or -1.
+func indexOf(list []interface{}, entryID string, id func(map[string]interface{}) string) int {
+	if entryID == "" {
+		return -1
+	}
+	for i, v := range list {
+		if entry, ok := v.(map[string]interface{}); ok && id(entry) == entryID {
+			return i
+		}
+	}
+	return -1
+}
+
+// hookID identifies a hook by name.
+func hookID(entry map[string]interface{}) string {
+	return stringValue(entry, "name")
+}
+
+// dependencyID identifies a dependency by name, then by block, then by path.
+func dependencyID(entry map[string]interface{}) string {
+	for _, key := range []string{"name", "block", "path"} {
+		if id := stringValue(entry, key); id != "" {
+			return key + ":" + id
+		}
+	}
+	return ""
+}
+
+// stringValue returns the string value of key in m, or an empty string.
+func stringValue(m map[string]interface{}, key string) string {
+	_, v, _ := utils.LookupKey(m, key)
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,102 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   map[string]interface{}
+		values map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name:   "value replaces base",
+			base:   map[string]interface{}{"template": "modules/a", "name": "a"},
+			values: map[string]interface{}{"template": "modules/b"},
+			want:   map[string]interface{}{"template": "modules/b", "name": "a"},
+		},
+		{
+			name:   "null unsets a field",
+			base:   map[string]interface{}{"template": "modules/a", "name": "a"},
+			values: map[string]interface{}{"template": nil},
+			want:   map[string]interface{}{"name": "a"},
+		},
+		{
+			name:   "keys match case-insensitively",
+			base:   map[string]interface{}{"Template": "modules/a"},
+			values: map[string]interface{}{"template": "modules/b"},
+			want:   map[string]interface{}{"template": "modules/b"},
+		},
+		{
+			name:   "maps merge recursively",
+			base:   map[string]interface{}{"inputs": map[string]interface{}{"labels": map[string]interface{}{"team": "a", "env": "dev"}, "size": 1}},
+			values: map[string]interface{}{"inputs": map[string]interface{}{"labels": map[string]interface{}{"env": "prod", "team": nil}}},
+			want:   map[string]interface{}{"inputs": map[string]interface{}{"labels": map[string]interface{}{"env": "prod"}, "size": 1}},
+		},
+		{
+			name:   "explicit inputs are replaced",
+			base:   map[string]interface{}{"inputs": map[string]interface{}{"a": map[string]interface{}{InputLiteral: "x"}}},
+			values: map[string]interface{}{"inputs": map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+			want:   map[string]interface{}{"inputs": map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+		},
+		{
+			name: "hooks merge by name",
+			base: map[string]interface{}{"beforeHooks": []interface{}{
+				map[string]interface{}{"name": "auth", "commands": []interface{}{"plan"}},
+				map[string]interface{}{"name": "lint"},
+			}},
+			values: map[string]interface{}{"beforeHooks": []interface{}{
+				map[string]interface{}{"name": "auth", "commands": []interface{}{"apply"}},
+				map[string]interface{}{"name": "fmt"},
+			}},
+			want: map[string]interface{}{"beforeHooks": []interface{}{
+				map[string]interface{}{"name": "auth", "commands": []interface{}{"apply"}},
+				map[string]interface{}{"name": "lint"},
+				map[string]interface{}{"name": "fmt"},
+			}},
+		},
+		{
+			name: "unset removes a dependency",
+			base: map[string]interface{}{"dependencies": []interface{}{
+				map[string]interface{}{"name": "network", "path": "../network"},
+				map[string]interface{}{"block": "db"},
+			}},
+			values: map[string]interface{}{"dependencies": []interface{}{
+				map[string]interface{}{"name": "network", UnsetKey: true},
+				map[string]interface{}{"block": "db", "withOutputs": true},
+			}},
+			want: map[string]interface{}{"dependencies": []interface{}{
+				map[string]interface{}{"block": "db", "withOutputs": true},
+			}},
+		},
+		{
+			name:   "unset of a missing entry is dropped",
+			base:   map[string]interface{}{},
+			values: map[string]interface{}{"dependencies": []interface{}{map[string]interface{}{"name": "network", UnsetKey: true}}},
+			want:   map[string]interface{}{"dependencies": []interface{}{}},
+		},
+		{
+			name:   "unset false keeps the entry",
+			base:   map[string]interface{}{"beforeHooks": []interface{}{map[string]interface{}{"name": "auth"}}},
+			values: map[string]interface{}{"beforeHooks": []interface{}{map[string]interface{}{"name": "auth", UnsetKey: false, "execute": []interface{}{"x"}}}},
+			want:   map[string]interface{}{"beforeHooks": []interface{}{map[string]interface{}{"name": "auth", "execute": []interface{}{"x"}}}},
+		},
+		{
+			name:   "null drops all entries",
+			base:   map[string]interface{}{"beforeHooks": []interface{}{map[string]interface{}{"name": "auth"}}},
+			values: map[string]interface{}{"beforeHooks": nil},
+			want:   map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeSpec(tt.base, tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeSpec() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package block
 
 import (
 	"fmt"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -10,9 +10,10 @@ import (
 	"github.com/romainframe/grunter/pkg/utils"
 )
 
-func transformSpecialPath(specialPath string) (string, error) {
-
-	specialPath, err := transformRelativePath(specialPath)
+// transformSpecialPath resolves the special path prefixes of specialPath from dir
+// and replaces the repository root by a get_repo_root() call.
+func transformSpecialPath(dir, specialPath string) (string, error) {
+	specialPath, err := transformRelativePath(dir, specialPath)
 	if err != nil {
 		return specialPath, err
 	}
@@ -24,13 +25,13 @@ func transformSpecialPath(specialPath string) (string, error) {
 	return specialPath, nil
 }
 
-func transformRelativePath(specialPath string) (string, error) {
+// transformRelativePath resolves a path starting with ".../<keyword>" to the first
+// <keyword> directory found from dir upwards.
+func transformRelativePath(dir, specialPath string) (string, error) {
 	if !strings.HasPrefix(specialPath, "...") {
 		return specialPath, nil
 	}
 
-	fmt.Printf("specialPath: %s\n", specialPath)
-
 	specialPath = strings.ReplaceAll(specialPath, ".../", "")
 
 	parts := strings.Split(specialPath, "/")
@@ -41,15 +42,11 @@ func transformRelativePath(specialPath string) (string, error) {
 	keyword := parts[0]
 	remainingParts := parts[1:]
 
-	currentDir, err := os.Getwd()
+	startDir, err := filepath.Abs(dir)
 	if err != nil {
 		return specialPath, err
 	}
 
-	startDir := filepath.Join(currentDir)
-
-	fmt.Printf("finding upwards from %s with keyword %s\n", startDir, keyword)
-
 	foundDir, err := utils.FindUpwards(startDir, keyword, 20)
 	if err != nil {
 		return specialPath, err
@@ -57,3 +54,153 @@ func transformRelativePath(specialPath string) (string, error) {
 
 	return filepath.Join(foundDir, strings.Join(remainingParts, "/")), nil
 }
+
+// resolveDependencyPath returns the config_path of a dependency of b. Dependencies on a block of the
+// system tree are resolved with resolveBlockReference, the others according to their path type:
+//   - relative: dep.Path is relative to the output directory of b, or absolute and made relative to it;
+//   - root: dep.Path is relative to the repository root and rendered with get_repo_root();
+//   - absolute: dep.Path is an absolute path;
+//   - block: dep.Path is the path of another block in the system tree, rendered relative to b.OutputDir;
+//   - unset: the special path prefixes are resolved and the repository root is replaced by get_repo_root().
+//
+// Paths holding interpolations cannot be checked; the others must exist, or be the output
+// directory of a block generated along with b. A dependency must have a block or a path.
+func resolveDependencyPath(b Block, dep Dependency) (string, error) {
+	if dep.Block != "" {
+		return b.resolveBlockReference(dep.Block)
+	}
+	if dep.Path == "" {
+		return "", ErrDependencyPathRequired
+	}
+
+	switch dep.PathType {
+	case "":
+		return transformSpecialPath(b.Dir, dep.Path)
+
+	case PathTypeRelative:
+		path, err := transformRelativePath(b.Dir, dep.Path)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(path) {
+			// Special prefixes are resolved from the block directory, or from the file declaring
+			// them beforehand (see RebaseSpec).
+			if path, err = relativeToOutputDir(b, path); err != nil {
+				return "", err
+			}
+		}
+		if location := filepath.Join(b.OutputDir, path); !hasInterpolation(path) && !b.targetExists(location) {
+			return "", ErrDependencyNotFound(dep.PathType, dep.Path, location)
+		}
+		return path, nil
+
+	case PathTypeRoot:
+		if env.GRUNT_REPO_ROOT == "" {
+			return "", ErrInvalidDependencyPath(dep.PathType, dep.Path, "repository root is not set")
+		}
+		path := dep.Path
+		if filepath.IsAbs(path) {
+			rel, err := filepath.Rel(env.GRUNT_REPO_ROOT, path)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				return "", ErrInvalidDependencyPath(dep.PathType, dep.Path, "path is outside of the repository")
+			}
+			path = rel
+		}
+		if location := filepath.Join(env.GRUNT_REPO_ROOT, path); !hasInterpolation(path) && !b.targetExists(location) {
+			return "", ErrDependencyNotFound(dep.PathType, dep.Path, location)
+		}
+		return "${get_repo_root()}/" + filepath.ToSlash(path), nil
+
+	case PathTypeAbsolute:
+		if !filepath.IsAbs(dep.Path) {
+			return "", ErrInvalidDependencyPath(dep.PathType, dep.Path, "path must be absolute")
+		}
+		if !hasInterpolation(dep.Path) && !b.targetExists(dep.Path) {
+			return "", ErrDependencyNotFound(dep.PathType, dep.Path, dep.Path)
+		}
+		return dep.Path, nil
+
+	case PathTypeBlock:
+		return b.resolveBlockReference("/" + dep.Path)
+
+	default:
+		return "", ErrUnknownPathType(dep.PathType)
+	}
+}
+
+// resolveBlockReference returns the config_path of the block designated by ref, relative to
+// b.OutputDir. Like a file path, ref is relative to the system holding b unless it starts with a
+// slash: cluster is a block of the same system, ../network/vpc a block of a sibling system.
+// A cell of a matrix is designated by its path after an @, such as db@prod/europe-west1; without
+// it, a block of a matrix depends on the same cell of a block with the same matrix, if any, and
+// the other blocks must designate a cell.
+func (b Block) resolveBlockReference(ref string) (string, error) {
+	name, cell, hasCell := strings.Cut(ref, "@")
+	if !strings.HasPrefix(name, "/") {
+		name = path.Join(b.System, name)
+	}
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", ErrInvalidBlockReference(ref)
+	}
+
+	target, ok := b.Targets[TargetName(path.Join(name, cell))]
+	if !ok && !hasCell {
+		target, ok = b.Targets[TargetName(path.Join(name, b.Cell))]
+		if !ok {
+			target, ok = b.Targets[TargetName(name)]
+		}
+	}
+	if !ok {
+		if cells, isMatrix := b.Matrices[TargetName(name)]; isMatrix && !hasCell {
+			return "", ErrMatrixBlockReference(ref, cells)
+		}
+		return "", ErrDependencyBlockNotFound(ref)
+	}
+	return relativeToOutputDir(b, target)
+}
+
+// TargetName returns the key of a block in the Targets of another block: its normalized path
+// in the system tree, without leading or trailing slashes.
+func TargetName(name string) string {
+	return strings.Trim(normalizeName(strings.Trim(name, "/")), "/")
+}
+
+// relativeToOutputDir returns path relative to the output directory of b, with forward slashes.
+func relativeToOutputDir(b Block, path string) (string, error) {
+	from, err := filepath.Abs(b.OutputDir)
+	if err != nil {
+		return "", err
+	}
+	to, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(from, to)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// targetExists reports whether path exists, or is the output directory of one of the Targets of b.
+func (b Block) targetExists(path string) bool {
+	if utils.DoesFileOrDirExists(path) {
+		return true
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, target := range b.Targets {
+		if t, err := filepath.Abs(target); err == nil && t == abs {
+			return true
+		}
+	}
+	return false
+}
+
+// hasInterpolation reports whether path holds an HCL interpolation, which cannot be resolved statically.
+func hasInterpolation(path string) bool {
+	return strings.Contains(path, "${")
+}
@@ -0,0 +1,70 @@
+package block
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// templatesKey is the key of the output templates of a block, as written in configuration files.
+const templatesKey = "templates"
+
+// RebaseSpec returns a copy of a block spec, as produced by a YAML or JSON unmarshaller, with the
+// paths relative to the file declaring it, found in dir, made absolute: the output templates and
+// the dependency paths starting with .../. The spec can then be merged into the spec of a file of
+// another directory, such as a block extending it or an Overlay patching it.
+func RebaseSpec(spec map[string]interface{}, dir string) (map[string]interface{}, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	rebased := utils.CopyMap(spec)
+
+	if key, value, ok := utils.LookupKey(spec, templatesKey); ok {
+		if templates, ok := value.(map[string]interface{}); ok {
+			templates = utils.CopyMap(templates)
+			for name, v := range templates {
+				if path, ok := v.(string); ok && path != "" && !filepath.IsAbs(path) {
+					templates[name] = filepath.Join(dir, path)
+				}
+			}
+			rebased[key] = templates
+		}
+	}
+
+	var errs utils.Errors
+	if key, value, ok := utils.LookupKey(spec, dependenciesKey); ok {
+		if list, ok := value.([]interface{}); ok {
+			dependencies := make([]interface{}, len(list))
+			for i, v := range list {
+				dependencies[i] = v
+				dep, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				switch stringValue(dep, "pathType") {
+				case "", PathTypeRelative:
+				default:
+					continue
+				}
+				pathKey, _, _ := utils.LookupKey(dep, "path")
+				path := stringValue(dep, "path")
+				if !strings.HasPrefix(path, "...") {
+					continue
+				}
+				resolved, err := transformRelativePath(dir, path)
+				if err != nil {
+					errs = errs.Append(utils.AtPath(fmt.Sprintf("%s[%d].path", dependenciesKey, i), err))
+					continue
+				}
+				dep = utils.CopyMap(dep)
+				dep[pathKey] = resolved
+				dependencies[i] = dep
+			}
+			rebased[key] = dependencies
+		}
+	}
+	return rebased, errs.ErrorOrNil()
+}
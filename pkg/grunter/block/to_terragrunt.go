@@ -2,9 +2,17 @@ package block
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/iancoleman/strcase"
+
+	"github.com/romainframe/grunter/pkg/env"
 	"github.com/romainframe/grunter/pkg/terragrunt"
 	"github.com/romainframe/grunter/pkg/utils"
 )
@@ -13,41 +21,40 @@ import (
 // It dynamically constructs the configuration by processing template sources, before hooks, dependencies,
 // local variables, and inputs. The function validates necessary fields and constructs the Terragrunt configuration
 // accordingly, incorporating all elements defined in the Grunter configuration into the Terragrunt configuration.
-func (b Block) GenTerragruntGrunt() (terragrunt.Config, error) {
+// The locals referenced but not defined are generated with resolvers.
+func (b Block) GenTerragruntGrunt(resolvers terragrunt.LocalResolvers) (terragrunt.Config, error) {
 	// Create a Terragrunt configuration with initialized fields to avoid nil map/slice errors.
 	tgConfig := terragrunt.Config{
 		Dependencies:   []terragrunt.Dependency{},
 		LocalVariables: []terragrunt.LocalVariable{},
-		Inputs:         map[string]string{},
+		Inputs:         map[string]interface{}{},
 		OpenTofu: terragrunt.OpenTofu{
 			BeforeHooks: []terragrunt.BeforeHook{},
 		},
 	}
 
 	// Initialize a new locals search object for collecting and merging local variables.
-	localsToSearch := terragrunt.NewLocalsSearch()
+	localsToSearch := terragrunt.NewLocalsSearch(terragrunt.LocalContext{
+		Metadata: b.Metadata,
+		Dir:      b.Dir,
+		RepoRoot: env.GRUNT_REPO_ROOT,
+	}, resolvers)
 
 	// Validate the Grunter configuration's template is provided and correctly format its source.
 	if b.Template == "" {
-		return tgConfig, ErrTemplateRequired
+		return tgConfig, utils.AtPath("template", ErrTemplateRequired)
 	}
 	tgConfig.OpenTofu.Source = formatTemplateSource(b.Template)
-	localsToSearch.Add(tgConfig.OpenTofu.Source)
+	localsToSearch.AddTemplate(tgConfig.OpenTofu.Source)
 
 	// Process the Grunter configuration elements, appending them to the Terragrunt configuration.
-	if err := processBeforeHooks(&tgConfig, b.BeforeHooks, localsToSearch); err != nil {
-		return tgConfig, err
-	}
-
-	if err := processDependencies(&tgConfig, b.Dependencies); err != nil {
-		return tgConfig, err
-	}
-
-	if err := processLocalVariables(&tgConfig, b.Locals, localsToSearch); err != nil {
-		return tgConfig, err
-	}
-
-	if err := processInputs(&tgConfig, b.Inputs, localsToSearch); err != nil {
+	// Every element is processed even when a previous one failed so that all problems are reported.
+	var errs utils.Errors
+	errs = errs.Append(processBeforeHooks(&tgConfig, b.BeforeHooks, localsToSearch))
+	errs = errs.Append(processDependencies(&tgConfig, b, b.Dependencies))
+	errs = errs.Append(processLocalVariables(&tgConfig, b.Locals, localsToSearch))
+	errs = errs.Append(processInputs(&tgConfig, b.Inputs, b.StrictInputs, localsToSearch))
+	if err := errs.ErrorOrNil(); err != nil {
 		return tgConfig, err
 	}
 
@@ -57,6 +64,16 @@ func (b Block) GenTerragruntGrunt() (terragrunt.Config, error) {
 		return tgConfig, err
 	}
 	tgConfig.LocalVariables = vars
+	tgConfig.Origin = terragrunt.Origin{
+		Name:     b.Name,
+		System:   b.System,
+		Template: b.Template,
+		Metadata: b.Metadata,
+	}
+	tgConfig.Templates = terragrunt.Templates{
+		Terragrunt: resolveTemplatePath(b.Dir, b.Templates.Terragrunt),
+		Values:     resolveTemplatePath(b.Dir, b.Templates.Values),
+	}
 
 	return tgConfig, nil
 }
@@ -71,24 +88,52 @@ func formatTemplateSource(template string) string {
 
 // processBeforeHooks processes before hooks for the Terragrunt configuration, adding them and collecting locals.
 func processBeforeHooks(grunt *terragrunt.Config, beforeHooks []BeforeHook, localsSearch terragrunt.LocalsSearch) error {
-	for _, bh := range beforeHooks {
+	var errs utils.Errors
+	for i, bh := range beforeHooks {
 		grunt.OpenTofu.BeforeHooks = append(grunt.OpenTofu.BeforeHooks, terragrunt.BeforeHook(bh))
-		if err := localsSearch.Add(bh.Execute...); err != nil {
-			return utils.WrapError(ErrProcessBeforeHooks(bh.Name), err)
+		if err := localsSearch.AddTemplate(bh.Execute...); err != nil {
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("beforeHooks[%d].execute", i), utils.WrapError(ErrProcessBeforeHooks(bh.Name), err)))
 		}
 	}
-	return nil
+	return errs.ErrorOrNil()
 }
 
 // processDependencies processes dependencies for the Terragrunt configuration, ensuring names are provided.
-func processDependencies(grunt *terragrunt.Config, dependencies []Dependency) error {
-	for _, dep := range dependencies {
+// A dependency on a block of the system tree is named after the block unless it has its own name.
+// Names must be unique, as terragrunt rejects two dependency blocks with the same name.
+func processDependencies(grunt *terragrunt.Config, b Block, dependencies []Dependency) error {
+	var errs utils.Errors
+	names := make(map[string]int, len(dependencies))
+	for i, dep := range dependencies {
+		depField := fmt.Sprintf("dependencies[%d]", i)
+		if dep.Block != "" {
+			if dep.Path != "" || dep.PathType != "" {
+				errs = errs.Append(utils.AtPath(depField+".block", utils.WrapError(ErrProcessBlockDependency(dep.Block), fmt.Errorf("block cannot be combined with path or pathType"))))
+				continue
+			}
+			if dep.Name == "" {
+				blockName, _, _ := strings.Cut(dep.Block, "@")
+				dep.Name = strcase.ToLowerCamel(path.Base(blockName))
+			}
+		}
 		if dep.Name == "" {
-			return utils.WrapError(ErrProcessDependencies(dep.Path), fmt.Errorf("dependency name is required"))
+			errs = errs.Append(utils.AtPath(depField+".name", utils.WrapError(ErrProcessDependencies(dep.Path), fmt.Errorf("dependency name is required"))))
+			continue
+		}
+		if first, ok := names[dep.Name]; ok {
+			errs = errs.Append(utils.AtPath(depField+".name", ErrDuplicateDependency(dep.Name, first)))
+			continue
 		}
-		depPath, err := transformSpecialPath(dep.Path)
+		names[dep.Name] = i
+
+		depPath, err := resolveDependencyPath(b, dep)
 		if err != nil {
-			return utils.WrapError(ErrProcessDependencies(dep.Path), err)
+			if dep.Block != "" {
+				errs = errs.Append(utils.AtPath(depField+".block", utils.WrapError(ErrProcessBlockDependency(dep.Block), err)))
+			} else {
+				errs = errs.Append(utils.AtPath(depField+".path", utils.WrapError(ErrProcessDependencies(dep.Path), err)))
+			}
+			continue
 		}
 		grunt.Dependencies = append(grunt.Dependencies, terragrunt.Dependency{
 			Name:        dep.Name,
@@ -96,57 +141,211 @@ func processDependencies(grunt *terragrunt.Config, dependencies []Dependency) er
 			SkipOutputs: !dep.WithOutputs,
 		})
 	}
-	return nil
+	return errs.ErrorOrNil()
 }
 
 // processLocalVariables processes local variables for the Terragrunt configuration, adding them and collecting locals.
+// Keys are sorted so that the generated locals keep the same order between runs.
 func processLocalVariables(grunt *terragrunt.Config, locals map[string]string, localsSearch terragrunt.LocalsSearch) error {
-	for localKey, localValue := range locals {
+	keys := make([]string, 0, len(locals))
+	for localKey := range locals {
+		keys = append(keys, localKey)
+	}
+	sort.Strings(keys)
+
+	var errs utils.Errors
+	for _, localKey := range keys {
+		localValue := locals[localKey]
 		grunt.LocalVariables = append(grunt.LocalVariables, terragrunt.LocalVariable{
 			Name:  localKey,
 			Value: localValue,
 		})
 		if err := localsSearch.Add(localValue); err != nil {
-			return utils.WrapError(ErrProcessLocals(localKey, localValue), err)
+			errs = errs.Append(utils.AtPath("locals."+localKey, utils.WrapError(ErrProcessLocals(localKey, localValue), err)))
 		}
 	}
-	return nil
+	return errs.ErrorOrNil()
 }
 
 // processInputs processes inputs for the Terragrunt configuration, adding them and collecting locals.
-func processInputs(grunt *terragrunt.Config, inputs map[string]string, localsSearch terragrunt.LocalsSearch) error {
-	for inKey, inValue := range inputs {
-		v := inValue
-		if strings.HasPrefix(inValue, "dependency.") {
-			grunt.Inputs[inKey] = inValue
-			continue
+// Inputs may be lists and maps: references are looked for in every string they hold, as strict
+// tells (see processInputString).
+func processInputs(grunt *terragrunt.Config, inputs map[string]interface{}, strict bool, localsSearch terragrunt.LocalsSearch) error {
+	keys := make([]string, 0, len(inputs))
+	for inKey := range inputs {
+		keys = append(keys, inKey)
+	}
+	sort.Strings(keys)
+
+	var errs utils.Errors
+	for _, inKey := range keys {
+		v, err := processInputValue(inKey, inputs[inKey], strict, localsSearch)
+		grunt.Inputs[inKey] = v
+		if err != nil {
+			errs = errs.Append(utils.AtPath("inputs."+inKey, err))
 		}
-		if strings.HasPrefix(inValue, "values.") {
-			v := "local.values.locals"
-			parts := strings.Split(inValue, ".")
-			for i, part := range parts {
-				if i > 0 && part != " " {
-					v = fmt.Sprintf("%s.%s", v, part)
-				}
-			}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Explicit input forms, written as a map holding a single one of these keys.
+const (
+	InputLiteral    = "$literal" // Quoted string, never turned into a reference.
+	InputReference  = "$ref"     // Reference such as values.name, project.slug or dependency.vpc.outputs.id.
+	InputExpression = "$expr"    // Raw HCL expression, such as merge(local.a, local.b).
+)
+
+// referenceRegex matches the dotted paths accepted as references.
+var referenceRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*(\.[a-zA-Z0-9_-]+)*$`)
+
+// referencePrefixes are the prefixes of the plain string inputs resolved as references in strict mode.
+var referencePrefixes = []string{"values.", "local.", "dependency."}
+
+// processInputValue returns value with every string it holds resolved (see processInputString),
+// descending into lists and maps. Errors are reported at the path of the offending element.
+func processInputValue(inKey string, value interface{}, strict bool, localsSearch terragrunt.LocalsSearch) (interface{}, error) {
+	switch inValue := value.(type) {
+	case string:
+		v := processInputString(inValue, strict)
+		if err := addInputLocals(localsSearch, v); err != nil {
+			return v, utils.WrapError(ErrProcessInput(inKey, inValue), err)
+		}
+		return v, nil
+	case []interface{}:
+		var errs utils.Errors
+		values := make([]interface{}, len(inValue))
+		for i, elem := range inValue {
+			var err error
+			values[i], err = processInputValue(inKey, elem, strict, localsSearch)
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("[%d]", i), err))
+		}
+		return values, errs.ErrorOrNil()
+	case map[string]interface{}:
+		if form, ok := ExplicitInputForm(inValue); ok {
+			return processExplicitInput(inKey, form, inValue[form], localsSearch)
+		}
+		var errs utils.Errors
+		values := make(map[string]interface{}, len(inValue))
+		for _, key := range sortedKeys(inValue) {
+			var err error
+			values[key], err = processInputValue(inKey, inValue[key], strict, localsSearch)
+			errs = errs.Append(utils.AtPath(key, err))
+		}
+		return values, errs.ErrorOrNil()
+	default:
+		return value, nil
+	}
+}
+
+// processInputString resolves a plain string input. Dotted paths such as values.name or
+// project.slug are references, a single word such as prod is a literal string, and anything
+// else is rendered as an HCL expression when it parses as one, such as get_env("REGION"), and
+// as a string otherwise. In strict mode, only the dotted paths starting with values., local. or
+// dependency. are references and anything else is a literal string. Use the explicit forms to
+// remove any ambiguity (see processExplicitInput).
+func processInputString(inValue string, strict bool) interface{} {
+	if referenceRegex.MatchString(inValue) {
+		if !strings.Contains(inValue, ".") {
+			return terragrunt.Literal(inValue)
 		}
-		// Local variable validation: a.b.d.c.d.e
-		validLocalDefRegex := regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)*$`)
-		if validLocalDefRegex.MatchString(inValue) {
-			parts := strings.Split(inValue, ".")
-			if len(parts) > 0 {
-				v = fmt.Sprintf("local.%s.locals", parts[0])
-				for i, part := range parts {
-					if i > 0 && part != " " {
-						v = fmt.Sprintf("%s.%s", v, part)
-					}
-				}
+		if !strict {
+			return resolveReference(inValue)
+		}
+		for _, prefix := range referencePrefixes {
+			if strings.HasPrefix(inValue, prefix) {
+				return resolveReference(inValue)
 			}
 		}
-		grunt.Inputs[inKey] = v
-		if err := localsSearch.Add(v); err != nil {
-			return utils.WrapError(ErrProcessInput(inKey, v), err)
+		return terragrunt.Literal(inValue)
+	}
+	if strict {
+		return terragrunt.Literal(inValue)
+	}
+	return inValue
+}
+
+// ExplicitInputForm returns the explicit form of an input written as a single key map.
+func ExplicitInputForm(value map[string]interface{}) (string, bool) {
+	if len(value) != 1 {
+		return "", false
+	}
+	for _, form := range []string{InputLiteral, InputReference, InputExpression} {
+		if _, ok := value[form]; ok {
+			return form, true
 		}
 	}
+	return "", false
+}
+
+// processExplicitInput resolves an input written with an explicit form, collecting the locals it uses.
+func processExplicitInput(inKey, form string, value interface{}, localsSearch terragrunt.LocalsSearch) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, utils.AtPath(form, ErrInvalidExplicitInput(form))
+	}
+
+	var v interface{}
+	switch form {
+	case InputLiteral:
+		v = terragrunt.Literal(s)
+	case InputReference:
+		if !referenceRegex.MatchString(s) {
+			return value, utils.AtPath(form, ErrInvalidReference(s))
+		}
+		v = resolveReference(s)
+	case InputExpression:
+		if _, diags := hclsyntax.ParseExpression([]byte(s), "", hcl.InitialPos); diags.HasErrors() {
+			return value, utils.AtPath(form, utils.WrapError(ErrInvalidExpression(s), diags))
+		}
+		v = terragrunt.Expression(s)
+	}
+
+	if err := addInputLocals(localsSearch, v); err != nil {
+		return v, utils.AtPath(form, utils.WrapError(ErrProcessInput(inKey, s), err))
+	}
+	return v, nil
+}
+
+// addInputLocals collects the locals used by a resolved input string.
+func addInputLocals(localsSearch terragrunt.LocalsSearch, value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return localsSearch.Add(v)
+	case terragrunt.Literal:
+		return localsSearch.AddTemplate(string(v))
+	case terragrunt.Expression:
+		return localsSearch.Add(string(v))
+	}
 	return nil
 }
+
+// resolveReference turns a reference into the local it designates: values.name becomes
+// local.values.locals.name, project becomes local.project, and local.* and dependency.* are kept as is.
+func resolveReference(ref string) terragrunt.Expression {
+	if strings.HasPrefix(ref, "local.") || strings.HasPrefix(ref, "dependency.") {
+		return terragrunt.Expression(ref)
+	}
+	parts := strings.Split(ref, ".")
+	if len(parts) == 1 {
+		return terragrunt.Expression("local." + ref)
+	}
+	return terragrunt.Expression(fmt.Sprintf("local.%s.locals.%s", parts[0], strings.Join(parts[1:], ".")))
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveTemplatePath returns the path of a template file relative to dir, or an empty path if none is set.
+func resolveTemplatePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
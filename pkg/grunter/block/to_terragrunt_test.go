@@ -0,0 +1,59 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/romainframe/grunter/pkg/terragrunt"
+)
+
+func TestProcessInputValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		strict  bool
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "single word", value: "prod", want: terragrunt.Literal("prod")},
+		{name: "values reference", value: "values.name", want: terragrunt.Expression("local.values.locals.name")},
+		{name: "dotted path", value: "project.slug", want: terragrunt.Expression("local.project.locals.slug")},
+		{name: "local reference", value: "local.env", want: terragrunt.Expression("local.env")},
+		{name: "dependency reference", value: "dependency.vpc.outputs.id", want: terragrunt.Expression("dependency.vpc.outputs.id")},
+		{name: "function call", value: `get_env("REGION")`, want: `get_env("REGION")`},
+		{name: "interpolation", value: "${local.env}-app", want: "${local.env}-app"},
+		{name: "free text", value: "hello world", want: "hello world"},
+
+		{name: "strict single word", value: "prod", strict: true, want: terragrunt.Literal("prod")},
+		{name: "strict values reference", value: "values.name", strict: true, want: terragrunt.Expression("local.values.locals.name")},
+		{name: "strict dotted path", value: "project.slug", strict: true, want: terragrunt.Literal("project.slug")},
+		{name: "strict dependency reference", value: "dependency.vpc.outputs.id", strict: true, want: terragrunt.Expression("dependency.vpc.outputs.id")},
+		{name: "strict function call", value: `get_env("REGION")`, strict: true, want: terragrunt.Literal(`get_env("REGION")`)},
+		{name: "strict interpolation", value: "${local.env}-app", strict: true, want: terragrunt.Literal("${local.env}-app")},
+
+		{name: "number", value: 3, want: 3},
+		{name: "boolean", value: true, want: true},
+		{name: "null", value: nil, want: nil},
+		{name: "list", value: []interface{}{"prod", "values.name", 1}, want: []interface{}{terragrunt.Literal("prod"), terragrunt.Expression("local.values.locals.name"), 1}},
+		{name: "map", value: map[string]interface{}{"team": "values.team"}, want: map[string]interface{}{"team": terragrunt.Expression("local.values.locals.team")}},
+
+		{name: "explicit literal", value: map[string]interface{}{InputLiteral: "values.prod"}, want: terragrunt.Literal("values.prod")},
+		{name: "explicit reference", value: map[string]interface{}{InputReference: "project"}, want: terragrunt.Expression("local.project")},
+		{name: "explicit expression", value: map[string]interface{}{InputExpression: "merge(local.a, local.b)"}, want: terragrunt.Expression("merge(local.a, local.b)")},
+		{name: "invalid reference", value: map[string]interface{}{InputReference: "not a reference"}, wantErr: true},
+		{name: "invalid expression", value: map[string]interface{}{InputExpression: "merge("}, wantErr: true},
+		{name: "explicit form not a string", value: map[string]interface{}{InputLiteral: 3}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processInputValue("input", tt.value, tt.strict, terragrunt.NewLocalsSearch(terragrunt.LocalContext{}, terragrunt.DefaultLocalResolvers()))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processInputValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("processInputValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
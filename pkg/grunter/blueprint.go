@@ -0,0 +1,563 @@
+package grunter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// parameterUse records how the parameter values of a Config are used while an object is built:
+// the parameters declared by the Blueprints instantiated, the values they take, and the problems
+// with those values. These problems are not found in a file, and are reported apart from the
+// problems of the object. A nil parameterUse records nothing.
+type parameterUse struct {
+	instantiated bool            // Whether a Blueprint was instantiated.
+	declared     map[string]bool // Names of the parameters declared by the Blueprints instantiated.
+	used         map[string]bool // Names of the values taken by a Blueprint.
+	errs         utils.Errors    // Values that could not be converted to the type of their parameter.
+}
+
+// newParameterUse returns an empty parameterUse.
+func newParameterUse() *parameterUse {
+	return &parameterUse{declared: map[string]bool{}, used: map[string]bool{}}
+}
+
+// declare records the instantiation of a Blueprint declaring the parameters names.
+func (u *parameterUse) declare(names []string) {
+	if u == nil {
+		return
+	}
+	u.instantiated = true
+	for _, name := range names {
+		u.declared[name] = true
+	}
+}
+
+// use records that the value of the parameter name was taken, or rejected with err.
+func (u *parameterUse) use(name string, err error) {
+	if u == nil {
+		return
+	}
+	u.used[name] = true
+	if err != nil {
+		u.errs = u.errs.Append(utils.WrapError(ErrSetValue(name), err))
+	}
+}
+
+// usedNames returns the sorted names of the values taken by a Blueprint.
+func (u *parameterUse) usedNames() []string {
+	if u == nil {
+		return nil
+	}
+	names := make([]string, 0, len(u.used))
+	for name := range u.used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// err returns the problems with the parameter values of config: the values rejected by their
+// parameter and, unless config.IgnoreUnknownParameters is set, the values set for no parameter of
+// the Blueprints instantiated.
+func (u *parameterUse) err(config Config) error {
+	if u == nil {
+		return nil
+	}
+	errs := u.errs
+	if u.instantiated && !config.IgnoreUnknownParameters {
+		declared := make([]string, 0, len(u.declared))
+		for name := range u.declared {
+			declared = append(declared, name)
+		}
+		sort.Strings(declared)
+		unknown := make([]string, 0, len(config.Parameters))
+		for name := range config.Parameters {
+			if !u.declared[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			errs = errs.Append(utils.WrapError(ErrSetValue(name), ErrUnknownParameter(name, declared)))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Parameter types.
+const (
+	ParameterTypeString  = "string"
+	ParameterTypeInteger = "integer"
+	ParameterTypeNumber  = "number"
+	ParameterTypeBoolean = "boolean"
+	ParameterTypeList    = "list"
+	ParameterTypeMap     = "map"
+)
+
+// parameterTypes lists the parameter types, in the order they are suggested.
+var parameterTypes = []string{
+	ParameterTypeString, ParameterTypeInteger, ParameterTypeNumber,
+	ParameterTypeBoolean, ParameterTypeList, ParameterTypeMap,
+}
+
+// Blueprint is the spec of a Blueprint object: the spec of a Block or a System whose strings
+// reference typed parameters, such as (( .service )), generated once the parameters are set by an
+// Instance or on the command line.
+type Blueprint struct {
+	Kind       string      `json:"kind"`       // Kind of the generated object: Block or System.
+	Parameters []Parameter `json:"parameters"` // Parameters of the Blueprint.
+	Template   interface{} `json:"template"`   // Spec of the generated object, rendered with the parameters.
+}
+
+// Parameter is a typed parameter of a Blueprint.
+type Parameter struct {
+	Name        string        `json:"name"`        // Name of the parameter, referenced as (( .name )).
+	Type        string        `json:"type"`        // Type of the parameter: string (default), integer, number, boolean, list or map.
+	Description string        `json:"description"` // What the parameter is for.
+	Default     interface{}   `json:"default"`     // Value of the parameter when it is not set.
+	Required    bool          `json:"required"`    // Whether the parameter must be set.
+	Enum        []interface{} `json:"enum"`        // Values the parameter is restricted to.
+	Pattern     string        `json:"pattern"`     // Regular expression a string parameter must match.
+	Minimum     *float64      `json:"minimum"`     // Minimum of an integer or number parameter.
+	Maximum     *float64      `json:"maximum"`     // Maximum of an integer or number parameter.
+}
+
+// Instance is the spec of an Instance object: a Blueprint and the values of its parameters.
+type Instance struct {
+	Blueprint string                 `json:"blueprint"` // Blueprint file instantiated, or catalog entry.
+	Values    map[string]interface{} `json:"values"`    // Values of the parameters, by name.
+}
+
+// parameterReference matches a string made of a single parameter reference, such as (( .replicas )),
+// replaced by the value of the parameter rather than its text, so that it keeps its type.
+var parameterReference = regexp.MustCompile(`^\(\(\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\)\)$`)
+
+// buildBlueprint generates the object of the Blueprint with the parameter values of its Config
+// and builds it like any other object.
+func (o Object) buildBlueprint() (Object, error) {
+	generated, err := o.resolveBlueprint()
+	if err != nil {
+		return Object{}, err
+	}
+	return generated.Build()
+}
+
+// resolveBlueprint returns the object generated by the Blueprint with the parameter values of its
+// Config.
+func (o Object) resolveBlueprint() (Object, error) {
+	generated, err := o.instantiate(nil)
+	if err != nil {
+		return Object{}, o.locate("spec", err)
+	}
+	return generated, nil
+}
+
+// buildInstance generates the object of the Blueprint of the Instance and builds it like any other object.
+func (o Object) buildInstance() (Object, error) {
+	generated, err := o.resolveInstance()
+	if err != nil {
+		return Object{}, err
+	}
+	return generated.Build()
+}
+
+// resolveInstance returns the object generated by the Blueprint of the Instance with its values,
+// overridden by the parameter values of its Config. The result is generated relative to the
+// directory of the Instance.
+func (o Object) resolveInstance() (Object, error) {
+	var instance Instance
+	if err := o.decode(o.Spec, &instance); err != nil {
+		return Object{}, err
+	}
+	if instance.Blueprint == "" {
+		return Object{}, o.locate("spec.blueprint", ErrInstanceBlueprintRequired)
+	}
+
+	bp, err := NewObjectFromFile(o.config.Catalog.objectPath(instance.Blueprint, o.dir))
+	if err != nil {
+		return Object{}, o.locate("spec.blueprint", utils.WrapErrors(ErrInstanceBlueprint(instance.Blueprint), err))
+	}
+	bp.config = o.config
+	bp.parameters = o.parameters
+	if bp.Kind != ObjectKindBlueprint {
+		return Object{}, o.locate("spec.blueprint", ErrNotBlueprint(instance.Blueprint, bp.Kind))
+	}
+
+	generated, err := bp.instantiate(instance.Values)
+	if err != nil {
+		return Object{}, o.locate("spec.values", err)
+	}
+
+	// The blocks of the generated object extend files relative to the Blueprint, and its relative
+	// paths are resolved from it before the result moves to the directory of the Instance.
+	var spec interface{}
+	switch generated.Kind {
+	case ObjectKindBlock:
+		spec, _, err = resolveExtends(o.config.Catalog, generated.Spec, bp.path, nil)
+	default:
+		spec, err = resolveSystemExtends(o.config.Catalog, generated.Spec, bp.path, "", map[string][]Layer{})
+	}
+	if err == nil {
+		spec, err = rebaseSpec(generated.Kind, spec, bp.dir)
+	}
+	if err != nil {
+		return Object{}, o.locate("spec.blueprint", utils.WrapErrors(ErrInstanceBlueprint(instance.Blueprint), bp.locate("spec.template", err)))
+	}
+
+	// The generated object is decoded strictly if either the Blueprint or the Instance requires it.
+	if o.strict() && !generated.strict() {
+		generated.ApiVersion = ApiVersionV2
+	}
+	generated.Spec = spec
+	generated.Metadata = o.Metadata
+	generated.dir = o.dir
+	generated.path = o.path
+	generated.positions = o.positions
+	return generated, nil
+}
+
+// instantiate returns the object generated by the Blueprint with the given parameter values,
+// overridden by the parameter values of its Config, written as on the command line. Problems with
+// the Blueprint itself are located in its file, problems with the values are reported at the path
+// of their name. The use of the values of the Config is recorded in o.parameters, which reports
+// their problems.
+func (o Object) instantiate(values map[string]interface{}) (Object, error) {
+	var bp Blueprint
+	if err := o.decode(o.Spec, &bp); err != nil {
+		return Object{}, err
+	}
+
+	// Check the Blueprint before its values.
+	var errs utils.Errors
+	switch bp.Kind {
+	case ObjectKindBlock, ObjectKindSystem:
+	default:
+		errs = errs.Append(o.locate("spec.kind", ErrBlueprintKind(bp.Kind)))
+	}
+	if bp.Template == nil {
+		errs = errs.Append(o.locate("spec.template", ErrBlueprintTemplateRequired))
+	}
+	declared := make(map[string]bool, len(bp.Parameters))
+	for i, p := range bp.Parameters {
+		field := fmt.Sprintf("spec.parameters[%d]", i)
+		if declared[p.Name] {
+			errs = errs.Append(o.locate(field+".name", ErrDuplicateParameter(p.Name)))
+			continue
+		}
+		declared[p.Name] = true
+		errs = errs.Append(o.locate(field, p.validate()))
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return Object{}, err
+	}
+
+	// Every value must set a parameter.
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range sortedNames(values) {
+		if !declared[name] {
+			errs = errs.Append(utils.AtPath(name, ErrUnknownParameter(name, names)))
+		}
+	}
+	o.parameters.declare(names)
+
+	// Resolve the value of every parameter, from the lowest precedence to the highest. A value of
+	// the Config that is rejected is recorded, and the parameter keeps its other value.
+	params := make(map[string]interface{}, len(bp.Parameters))
+	for _, p := range bp.Parameters {
+		value, set := p.Default, p.Default != nil
+		if v, ok := values[p.Name]; ok {
+			value, set = v, true
+		}
+		if text, ok := o.config.Parameters[p.Name]; ok {
+			parsed, err := p.parse(text)
+			if err == nil {
+				parsed, err = p.check(parsed)
+			}
+			o.parameters.use(p.Name, err)
+			if err == nil {
+				params[p.Name] = parsed
+				continue
+			}
+		}
+		if !set {
+			if p.Required {
+				errs = errs.Append(utils.AtPath(p.Name, ErrParameterRequired(p.Name)))
+				continue
+			}
+			value = p.zero()
+		}
+
+		checked, err := p.check(value)
+		if err != nil {
+			errs = errs.Append(utils.AtPath(p.Name, err))
+			continue
+		}
+		params[p.Name] = checked
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return Object{}, err
+	}
+
+	spec, err := render("", bp.Template, params)
+	if err != nil {
+		return Object{}, o.locate("spec.template", err)
+	}
+	return Object{
+		ApiVersion: o.ApiVersion,
+		Kind:       bp.Kind,
+		Metadata:   o.Metadata,
+		Spec:       spec,
+		dir:        o.dir,
+		path:       o.path,
+		positions:  o.positions,
+		config:     o.config,
+		parameters: o.parameters,
+	}, nil
+}
+
+// validate checks the declaration of the parameter: its name, its type, and that its constraints
+// apply to its type and hold for its default and allowed values.
+func (p Parameter) validate() error {
+	if p.Name == "" {
+		return utils.AtPath("name", ErrParameterNameRequired)
+	}
+
+	var errs utils.Errors
+	switch p.Type {
+	case "", ParameterTypeString, ParameterTypeInteger, ParameterTypeNumber,
+		ParameterTypeBoolean, ParameterTypeList, ParameterTypeMap:
+	default:
+		return utils.AtPath("type", ErrUnknownParameterType(p.Type, parameterTypes))
+	}
+	if p.Pattern != "" {
+		if p.kind() != ParameterTypeString {
+			errs = errs.Append(utils.AtPath("pattern", ErrParameterConstraint("pattern", p.kind())))
+		} else if _, err := regexp.Compile(p.Pattern); err != nil {
+			errs = errs.Append(utils.AtPath("pattern", utils.WrapError(ErrInvalidPattern(p.Pattern), err)))
+		}
+	}
+	if p.Minimum != nil && !p.numeric() {
+		errs = errs.Append(utils.AtPath("minimum", ErrParameterConstraint("minimum", p.kind())))
+	}
+	if p.Maximum != nil && !p.numeric() {
+		errs = errs.Append(utils.AtPath("maximum", ErrParameterConstraint("maximum", p.kind())))
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	// The allowed values must be of the type of the parameter, and the default one of them.
+	unconstrained := p
+	unconstrained.Enum = nil
+	for i, value := range p.Enum {
+		if _, err := unconstrained.check(value); err != nil {
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("enum[%d]", i), err))
+		}
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return err
+	}
+	if p.Default != nil {
+		if _, err := p.check(p.Default); err != nil {
+			return utils.AtPath("default", err)
+		}
+	}
+	return nil
+}
+
+// kind returns the type of the parameter, string when it is not set.
+func (p Parameter) kind() string {
+	if p.Type == "" {
+		return ParameterTypeString
+	}
+	return p.Type
+}
+
+// numeric reports whether the parameter is an integer or a number.
+func (p Parameter) numeric() bool {
+	return p.kind() == ParameterTypeInteger || p.kind() == ParameterTypeNumber
+}
+
+// zero returns the value of an optional parameter without default that is not set.
+func (p Parameter) zero() interface{} {
+	switch p.kind() {
+	case ParameterTypeInteger, ParameterTypeNumber:
+		return 0
+	case ParameterTypeBoolean:
+		return false
+	case ParameterTypeList:
+		return []interface{}{}
+	case ParameterTypeMap:
+		return map[string]interface{}{}
+	default:
+		return ""
+	}
+}
+
+// parse converts text, a value written on the command line, to the type of the parameter.
+// Lists and maps are written in YAML flow style, such as [a, b] or {team: data}.
+func (p Parameter) parse(text string) (interface{}, error) {
+	switch p.kind() {
+	case ParameterTypeInteger:
+		return strconv.Atoi(text)
+	case ParameterTypeNumber:
+		return strconv.ParseFloat(text, 64)
+	case ParameterTypeBoolean:
+		return strconv.ParseBool(text)
+	case ParameterTypeList, ParameterTypeMap:
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return text, nil
+	}
+}
+
+// check returns value, normalized to the Go type of the type of the parameter, or an error if it is
+// not of that type or breaks a constraint of the parameter.
+func (p Parameter) check(value interface{}) (interface{}, error) {
+	switch p.kind() {
+	case ParameterTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, ErrParameterType(value, p.kind())
+		}
+		if p.Pattern != "" && !regexp.MustCompile(p.Pattern).MatchString(s) {
+			return nil, ErrParameterPattern(s, p.Pattern)
+		}
+	case ParameterTypeInteger, ParameterTypeNumber:
+		var n float64
+		switch v := value.(type) {
+		case int:
+			n = float64(v)
+		case int64:
+			n, value = float64(v), int(v)
+		case float64:
+			n = v
+			if p.kind() == ParameterTypeInteger {
+				if v != float64(int(v)) {
+					return nil, ErrParameterType(value, p.kind())
+				}
+				value = int(v)
+			}
+		default:
+			return nil, ErrParameterType(value, p.kind())
+		}
+		if p.Minimum != nil && n < *p.Minimum {
+			return nil, ErrParameterMinimum(value, *p.Minimum)
+		}
+		if p.Maximum != nil && n > *p.Maximum {
+			return nil, ErrParameterMaximum(value, *p.Maximum)
+		}
+	case ParameterTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return nil, ErrParameterType(value, p.kind())
+		}
+	case ParameterTypeList:
+		if _, ok := value.([]interface{}); !ok {
+			return nil, ErrParameterType(value, p.kind())
+		}
+	case ParameterTypeMap:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, ErrParameterType(value, p.kind())
+		}
+	}
+
+	// Allowed values are compared as written, so that 3 and 3.0 are the same number.
+	if len(p.Enum) > 0 {
+		allowed := make([]string, len(p.Enum))
+		for i, v := range p.Enum {
+			allowed[i] = fmt.Sprint(v)
+			if allowed[i] == fmt.Sprint(value) {
+				return value, nil
+			}
+		}
+		return nil, ErrParameterEnum(value, allowed)
+	}
+	return value, nil
+}
+
+// render returns value, found at field in the template of a Blueprint, with its strings and map
+// keys executed as Go templates of the parameters, delimited by (( and )). A string made of a single
+// parameter reference is replaced by the value of the parameter. Every problem found is reported.
+func render(field string, value interface{}, params map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		result, err := renderString(v, params)
+		return result, utils.AtPath(field, err)
+	case []interface{}:
+		var errs utils.Errors
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			result[i], err = render(fmt.Sprintf("%s[%d]", field, i), item, params)
+			errs = errs.Append(err)
+		}
+		return result, errs.ErrorOrNil()
+	case map[string]interface{}:
+		var errs utils.Errors
+		result := make(map[string]interface{}, len(v))
+		for _, key := range sortedNames(v) {
+			name, err := renderString(key, params)
+			if err != nil {
+				errs = errs.Append(utils.AtPath(utils.JoinPath(field, key), err))
+				continue
+			}
+			s, ok := name.(string)
+			if !ok {
+				s = fmt.Sprint(name)
+			}
+			result[s], err = render(utils.JoinPath(field, key), v[key], params)
+			errs = errs.Append(err)
+		}
+		return result, errs.ErrorOrNil()
+	default:
+		return value, nil
+	}
+}
+
+// renderString executes value as a Go template of the parameters, unless it holds no ((.
+// A single parameter reference is replaced by the value of the parameter.
+func renderString(value string, params map[string]interface{}) (interface{}, error) {
+	if !strings.Contains(value, "((") {
+		return value, nil
+	}
+	if match := parameterReference.FindStringSubmatch(value); match != nil {
+		if param, ok := params[match[1]]; ok {
+			return utils.DeepCopy(param), nil
+		}
+	}
+	tmpl, err := template.New("value").Delims("((", "))").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return value, utils.WrapError(ErrRenderParameter(value), err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, params); err != nil {
+		return value, utils.WrapError(ErrRenderParameter(value), err)
+	}
+	return out.String(), nil
+}
+
+// sortedNames returns the keys of m, sorted.
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,182 @@
+package grunter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParameterValidate(t *testing.T) {
+	number := func(n float64) *float64 { return &n }
+	tests := []struct {
+		name      string
+		parameter Parameter
+		wantErr   bool
+	}{
+		{name: "string", parameter: Parameter{Name: "service", Pattern: "^[a-z]+$", Default: "api"}},
+		{name: "integer in range", parameter: Parameter{Name: "replicas", Type: ParameterTypeInteger, Minimum: number(1), Maximum: number(5), Default: 2}},
+		{name: "enum", parameter: Parameter{Name: "env", Enum: []interface{}{"dev", "prod"}, Default: "dev"}},
+		{name: "no name", parameter: Parameter{Type: ParameterTypeString}, wantErr: true},
+		{name: "unknown type", parameter: Parameter{Name: "a", Type: "float"}, wantErr: true},
+		{name: "pattern on an integer", parameter: Parameter{Name: "a", Type: ParameterTypeInteger, Pattern: "^1$"}, wantErr: true},
+		{name: "invalid pattern", parameter: Parameter{Name: "a", Pattern: "("}, wantErr: true},
+		{name: "minimum on a boolean", parameter: Parameter{Name: "a", Type: ParameterTypeBoolean, Minimum: number(0)}, wantErr: true},
+		{name: "maximum on a list", parameter: Parameter{Name: "a", Type: ParameterTypeList, Maximum: number(0)}, wantErr: true},
+		{name: "enum value of another type", parameter: Parameter{Name: "a", Type: ParameterTypeInteger, Enum: []interface{}{1, "two"}}, wantErr: true},
+		{name: "default of another type", parameter: Parameter{Name: "a", Type: ParameterTypeBoolean, Default: "yes"}, wantErr: true},
+		{name: "default out of range", parameter: Parameter{Name: "a", Type: ParameterTypeNumber, Maximum: number(1), Default: 1.5}, wantErr: true},
+		{name: "default not allowed", parameter: Parameter{Name: "a", Enum: []interface{}{"dev"}, Default: "prod"}, wantErr: true},
+		{name: "default not matching", parameter: Parameter{Name: "a", Pattern: "^[a-z]+$", Default: "API"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parameter.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParameterParse(t *testing.T) {
+	number := func(n float64) *float64 { return &n }
+	tests := []struct {
+		name      string
+		parameter Parameter
+		text      string
+		want      interface{}
+		wantErr   bool
+	}{
+		{name: "string", parameter: Parameter{Name: "a"}, text: "3", want: "3"},
+		{name: "integer", parameter: Parameter{Name: "a", Type: ParameterTypeInteger}, text: "3", want: 3},
+		{name: "number", parameter: Parameter{Name: "a", Type: ParameterTypeNumber}, text: "0.5", want: 0.5},
+		{name: "boolean", parameter: Parameter{Name: "a", Type: ParameterTypeBoolean}, text: "true", want: true},
+		{name: "list", parameter: Parameter{Name: "a", Type: ParameterTypeList}, text: "[a, b]", want: []interface{}{"a", "b"}},
+		{name: "map", parameter: Parameter{Name: "a", Type: ParameterTypeMap}, text: "{team: data}", want: map[string]interface{}{"team": "data"}},
+		{name: "not an integer", parameter: Parameter{Name: "a", Type: ParameterTypeInteger}, text: "3.5", wantErr: true},
+		{name: "not a boolean", parameter: Parameter{Name: "a", Type: ParameterTypeBoolean}, text: "yes please", wantErr: true},
+		{name: "not a list", parameter: Parameter{Name: "a", Type: ParameterTypeList}, text: "{team: data}", wantErr: true},
+		{name: "below minimum", parameter: Parameter{Name: "a", Type: ParameterTypeInteger, Minimum: number(1)}, text: "0", wantErr: true},
+		{name: "not allowed", parameter: Parameter{Name: "a", Enum: []interface{}{"dev", "prod"}}, text: "qa", wantErr: true},
+		{name: "allowed number written differently", parameter: Parameter{Name: "a", Type: ParameterTypeNumber, Enum: []interface{}{3}}, text: "3.0", want: 3.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parameter.parse(tt.text)
+			if err == nil {
+				got, err = tt.parameter.check(got)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	spec := map[string]interface{}{
+		"kind": ObjectKindBlock,
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "service", "required": true},
+			map[string]interface{}{"name": "replicas", "type": ParameterTypeInteger, "default": 1, "maximum": 5},
+		},
+		"template": map[string]interface{}{
+			"name":   "(( .service ))-app",
+			"inputs": map[string]interface{}{"replicas": "(( .replicas ))"},
+		},
+	}
+	tests := []struct {
+		name       string
+		values     map[string]interface{}
+		parameters map[string]string
+		ignore     bool
+		want       interface{}
+		wantErr    bool
+		wantSetErr bool
+		wantUsed   []string
+	}{
+		{
+			name:     "instance values",
+			values:   map[string]interface{}{"service": "api", "replicas": 2},
+			want:     map[string]interface{}{"name": "api-app", "inputs": map[string]interface{}{"replicas": 2}},
+			wantUsed: []string{},
+		},
+		{
+			name:       "set values override instance values",
+			values:     map[string]interface{}{"service": "api", "replicas": 2},
+			parameters: map[string]string{"replicas": "3"},
+			want:       map[string]interface{}{"name": "api-app", "inputs": map[string]interface{}{"replicas": 3}},
+			wantUsed:   []string{"replicas"},
+		},
+		{
+			name:       "set value for a required parameter",
+			parameters: map[string]string{"service": "web"},
+			want:       map[string]interface{}{"name": "web-app", "inputs": map[string]interface{}{"replicas": 1}},
+			wantUsed:   []string{"service"},
+		},
+		{
+			name:       "invalid set value keeps the instance value",
+			values:     map[string]interface{}{"service": "api", "replicas": 2},
+			parameters: map[string]string{"replicas": "6"},
+			want:       map[string]interface{}{"name": "api-app", "inputs": map[string]interface{}{"replicas": 2}},
+			wantSetErr: true,
+			wantUsed:   []string{"replicas"},
+		},
+		{
+			name:       "unknown set value",
+			values:     map[string]interface{}{"service": "api"},
+			parameters: map[string]string{"region": "eu"},
+			want:       map[string]interface{}{"name": "api-app", "inputs": map[string]interface{}{"replicas": 1}},
+			wantSetErr: true,
+			wantUsed:   []string{},
+		},
+		{
+			name:       "unknown set value ignored",
+			values:     map[string]interface{}{"service": "api"},
+			parameters: map[string]string{"region": "eu"},
+			ignore:     true,
+			want:       map[string]interface{}{"name": "api-app", "inputs": map[string]interface{}{"replicas": 1}},
+			wantUsed:   []string{},
+		},
+		{
+			name:     "missing required parameter",
+			values:   map[string]interface{}{"replicas": 2},
+			wantErr:  true,
+			wantUsed: []string{},
+		},
+		{
+			name:     "unknown instance value",
+			values:   map[string]interface{}{"service": "api", "region": "eu"},
+			wantErr:  true,
+			wantUsed: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.IgnoreUnknownParameters = tt.ignore
+			if tt.parameters != nil {
+				config.Parameters = tt.parameters
+			}
+			o := Object{ApiVersion: ApiVersionV2, Kind: ObjectKindBlueprint, Spec: spec, config: config, parameters: newParameterUse()}
+
+			generated, err := o.instantiate(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("instantiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(generated.Spec, tt.want) {
+				t.Errorf("instantiate() = %#v, want %#v", generated.Spec, tt.want)
+			}
+			if err := o.parameters.err(config); (err != nil) != tt.wantSetErr {
+				t.Errorf("parameter values error = %v, wantErr %v", err, tt.wantSetErr)
+			}
+			if got := o.parameters.usedNames(); !reflect.DeepEqual(got, tt.wantUsed) {
+				t.Errorf("usedNames() = %q, want %q", got, tt.wantUsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package grunter
+
+import (
+	"path/filepath"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/terragrunt"
+)
+
+// Config holds the settings objects are built and rendered with. It is passed to NewWithConfig,
+// and on to every object, block and template involved, so that several configurations can be
+// used in the same process. Start from DefaultConfig.
+type Config struct {
+	// Templates holds the template files every object is rendered with, unless one of its
+	// blocks overrides them. Empty paths fall back to the built-in templates.
+	Templates terragrunt.Templates
+	// Catalog holds the object files referenced by name.
+	Catalog Catalog
+	// Layout is the layout of the Systems that do not set one, system.DefaultLayout when empty.
+	Layout string
+	// Builders are the extra builders every block is built with, such as block.NewK8sGruntBuilder.
+	Builders []block.GruntBuilder
+	// Locals resolve the locals blocks reference without defining them.
+	Locals terragrunt.LocalResolvers
+	// Parameters holds parameter values of Blueprints, such as replicas: "3", as written on the
+	// command line. They override the values of Instances and the defaults of Blueprints, and
+	// are converted to the type of their parameter.
+	Parameters map[string]string
+	// IgnoreUnknownParameters makes Blueprints ignore the Parameters they do not declare instead
+	// of rejecting them, as when a whole tree is generated. See Grunter.UsedParameters.
+	IgnoreUnknownParameters bool
+}
+
+// DefaultConfig returns the built-in settings.
+func DefaultConfig() Config {
+	return Config{
+		Catalog:    Catalog{},
+		Locals:     terragrunt.DefaultLocalResolvers(),
+		Parameters: map[string]string{},
+	}
+}
+
+// Catalog maps the names a block can extend, an Overlay can patch or an Instance can instantiate,
+// to object files, such as base: /repo/catalog/base/block.yaml.
+type Catalog map[string]string
+
+// objectPath returns the path of the object file designated by ref: an entry of the Catalog, or
+// a path relative to dir.
+func (c Catalog) objectPath(ref, dir string) string {
+	if path, ok := c[ref]; ok {
+		return path
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(dir, ref)
+}
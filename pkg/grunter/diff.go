@@ -0,0 +1,63 @@
+package grunter
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// FileDiff describes how a rendered file differs from the file on disk.
+type FileDiff struct {
+	Path    string // Path of the file, relative to the working directory.
+	Created bool   // Whether the file does not exist yet.
+	Diff    string // Unified diff from the file on disk to the rendered file.
+}
+
+// Diff renders every file Gen would write and compares them with the files on disk,
+// without writing anything. Only the files that Gen would change are returned.
+func (g Grunter) Diff(outputPath string) ([]FileDiff, error) {
+	files, err := g.Render(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDiff
+	for _, file := range files {
+		exists := utils.DoesFileOrDirExists(file.Path)
+		if file.KeepExisting && exists {
+			continue
+		}
+
+		var current []byte
+		if exists {
+			current, err = os.ReadFile(file.Path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if exists && bytes.Equal(current, file.Content) {
+			continue
+		}
+
+		fromFile, fromLines := "a/"+file.Path, difflib.SplitLines(string(current))
+		if !exists {
+			fromFile, fromLines = "/dev/null", nil
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        fromLines,
+			B:        difflib.SplitLines(string(file.Content)),
+			FromFile: fromFile,
+			ToFile:   "b/" + file.Path,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, FileDiff{Path: file.Path, Created: !exists, Diff: diff})
+	}
+
+	return diffs, nil
+}
@@ -1,10 +1,178 @@
 package grunter
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Predefined errors for file operations.
 var (
 
 	// ErrCreationFailed is returned when the grunter cannot be created.
 	ErrCreationFailed = fmt.Errorf("failed to create grunter")
+
+	// ErrParseConfig is returned when the config file cannot be parsed.
+	ErrParseConfig = fmt.Errorf("could not parse config file")
+
+	// ErrConvertConfig is returned when the config cannot be converted to Terragrunt configurations.
+	ErrConvertConfig = fmt.Errorf("could not convert config to terragrunt config")
+
+	// ErrReadTemplate is returned when a template file cannot be read.
+	ErrReadTemplate = func(path string) error {
+		return fmt.Errorf("could not read template '%s'", path)
+	}
+
+	// ErrParseTemplate is returned when a template cannot be parsed.
+	ErrParseTemplate = func(name string) error {
+		return fmt.Errorf("could not parse %s template", name)
+	}
+
+	// ErrRenderConfig is returned when a Terragrunt configuration cannot be rendered.
+	ErrRenderConfig = func(path string) error {
+		return fmt.Errorf("could not render terragrunt configuration '%s'", path)
+	}
+
+	// ErrDependencyCycle is returned when generated configurations depend on each other in a cycle.
+	ErrDependencyCycle = func(path []string) error {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(path, " -> "))
+	}
+
+	// ErrGraphFormat is returned when a graph is exported in an unknown format.
+	ErrGraphFormat = func(format string) error {
+		return fmt.Errorf("unknown graph format '%s', expected one of: dot, mermaid, json", format)
+	}
+
+	// ErrExtends is returned when the block file a block extends cannot be loaded.
+	ErrExtends = func(ref string) error {
+		return fmt.Errorf("could not extend '%s'", ref)
+	}
+
+	// ErrInvalidExtends is returned when extends is not the name or the path of a block file.
+	ErrInvalidExtends = func(value string) error {
+		return fmt.Errorf("invalid extends '%s', expected the path of a block file or the name of a catalog entry", value)
+	}
+
+	// ErrExtendsKind is returned when a block extends an object that is not a block.
+	ErrExtendsKind = func(ref, kind string) error {
+		return fmt.Errorf("'%s' is a %s, only a Block can be extended", ref, kind)
+	}
+
+	// ErrExtendsCycle is returned when block files extend each other in a cycle.
+	ErrExtendsCycle = func(path []string) error {
+		return fmt.Errorf("extends cycle: %s", strings.Join(path, " -> "))
+	}
+
+	// ErrOverlayBaseRequired is returned when an Overlay has no base object.
+	ErrOverlayBaseRequired = fmt.Errorf("base is required")
+
+	// ErrOverlayBase is returned when the base object of an Overlay cannot be loaded.
+	ErrOverlayBase = func(ref string) error {
+		return fmt.Errorf("could not load base '%s'", ref)
+	}
+
+	// ErrOverlayCycle is returned when Overlays are based on each other in a cycle.
+	ErrOverlayCycle = func(path []string) error {
+		return fmt.Errorf("overlay cycle: %s", strings.Join(path, " -> "))
+	}
+
+	// ErrInvalidPatch is returned when a patch is neither a strategic merge patch nor a JSON Patch operation.
+	ErrInvalidPatch = fmt.Errorf("a patch must set either merge or op")
+
+	// ErrExplainOverlay is returned when an Overlay is explained.
+	ErrExplainOverlay = fmt.Errorf("an Overlay cannot be explained, explain its base object instead")
+
+	// ErrExplainBlueprint is returned when a Blueprint or an Instance is explained.
+	ErrExplainBlueprint = fmt.Errorf("a Blueprint or an Instance cannot be explained")
+
+	// ErrBlueprintKind is returned when a Blueprint generates an object that is neither a Block nor a System.
+	ErrBlueprintKind = func(kind string) error {
+		return fmt.Errorf("invalid kind '%s', a Blueprint generates a Block or a System", kind)
+	}
+
+	// ErrBlueprintTemplateRequired is returned when a Blueprint has no template.
+	ErrBlueprintTemplateRequired = fmt.Errorf("template is required")
+
+	// ErrParameterNameRequired is returned when a parameter of a Blueprint has no name.
+	ErrParameterNameRequired = fmt.Errorf("name is required")
+
+	// ErrDuplicateParameter is returned when a Blueprint declares a parameter more than once.
+	ErrDuplicateParameter = func(name string) error {
+		return fmt.Errorf("parameter '%s' is declared more than once", name)
+	}
+
+	// ErrUnknownParameterType is returned when a parameter has an unknown type.
+	ErrUnknownParameterType = func(kind string, kinds []string) error {
+		return fmt.Errorf("unknown parameter type '%s', expected one of: %s", kind, strings.Join(kinds, ", "))
+	}
+
+	// ErrParameterConstraint is returned when a constraint does not apply to the type of its parameter.
+	ErrParameterConstraint = func(constraint, kind string) error {
+		return fmt.Errorf("%s does not apply to a %s parameter", constraint, kind)
+	}
+
+	// ErrInvalidPattern is returned when the pattern of a parameter is not a valid regular expression.
+	ErrInvalidPattern = func(pattern string) error {
+		return fmt.Errorf("invalid pattern '%s'", pattern)
+	}
+
+	// ErrParameterType is returned when the value of a parameter is not of its type.
+	ErrParameterType = func(value interface{}, kind string) error {
+		return fmt.Errorf("'%v' is not of type %s", value, kind)
+	}
+
+	// ErrParameterPattern is returned when the value of a string parameter does not match its pattern.
+	ErrParameterPattern = func(value, pattern string) error {
+		return fmt.Errorf("'%s' does not match '%s'", value, pattern)
+	}
+
+	// ErrParameterMinimum is returned when the value of a parameter is less than its minimum.
+	ErrParameterMinimum = func(value interface{}, minimum float64) error {
+		return fmt.Errorf("%v is less than the minimum %v", value, minimum)
+	}
+
+	// ErrParameterMaximum is returned when the value of a parameter is greater than its maximum.
+	ErrParameterMaximum = func(value interface{}, maximum float64) error {
+		return fmt.Errorf("%v is greater than the maximum %v", value, maximum)
+	}
+
+	// ErrParameterEnum is returned when the value of a parameter is not one of its allowed values.
+	ErrParameterEnum = func(value interface{}, allowed []string) error {
+		return fmt.Errorf("'%v' is not one of: %s", value, strings.Join(allowed, ", "))
+	}
+
+	// ErrParameterRequired is returned when a required parameter is not set.
+	ErrParameterRequired = func(name string) error {
+		return fmt.Errorf("parameter '%s' is required", name)
+	}
+
+	// ErrUnknownParameter is returned when a value is set for a parameter the Blueprint does not declare.
+	ErrUnknownParameter = func(name string, names []string) error {
+		if len(names) == 0 {
+			return fmt.Errorf("unknown parameter '%s', the Blueprint has no parameters", name)
+		}
+		return fmt.Errorf("unknown parameter '%s', expected one of: %s", name, strings.Join(names, ", "))
+	}
+
+	// ErrSetValue is returned when a value set on the command line cannot be applied.
+	ErrSetValue = func(name string) error {
+		return fmt.Errorf("invalid --set value for '%s'", name)
+	}
+
+	// ErrRenderParameter is returned when a value of the template of a Blueprint cannot be rendered.
+	ErrRenderParameter = func(value string) error {
+		return fmt.Errorf("could not render '%s'", value)
+	}
+
+	// ErrInstanceBlueprintRequired is returned when an Instance has no Blueprint.
+	ErrInstanceBlueprintRequired = fmt.Errorf("blueprint is required")
+
+	// ErrInstanceBlueprint is returned when the Blueprint of an Instance cannot be loaded.
+	ErrInstanceBlueprint = func(ref string) error {
+		return fmt.Errorf("could not load blueprint '%s'", ref)
+	}
+
+	// ErrNotBlueprint is returned when an Instance references an object that is not a Blueprint.
+	ErrNotBlueprint = func(ref, kind string) error {
+		return fmt.Errorf("'%s' is a %s, an Instance must reference a Blueprint", ref, kind)
+	}
 )
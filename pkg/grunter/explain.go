@@ -0,0 +1,178 @@
+package grunter
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/grunter/system"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Explanation tells which file each field of a block comes from.
+type Explanation struct {
+	Block  string        // Name of the block, with the path of its system.
+	Files  []string      // Files the block is merged from, from the furthest extended file to the one defining it.
+	Fields []FieldOrigin // Fields of the block, sorted by path.
+}
+
+// FieldOrigin is a field of a block and the file it comes from.
+type FieldOrigin struct {
+	Path  string      // Path of the field, such as inputs.labels.team or dependencies[network].
+	Value interface{} // Value of the field.
+	File  string      // File setting the value.
+}
+
+// Explain returns the explanation of every block of the object at configPath, after extends and
+// system defaults are merged in, with the catalog of config. Fields inherited from the defaults of
+// a system are attributed to the system file.
+func Explain(configPath string, config Config) ([]Explanation, error) {
+	obj, err := NewObjectFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch obj.Kind {
+	case ObjectKindBlock:
+		spec, layers, err := resolveExtends(config.Catalog, obj.Spec, obj.path, nil)
+		if err != nil {
+			return nil, obj.locate("spec", err)
+		}
+		values, _ := spec.(map[string]interface{})
+		return []Explanation{explain("", values, layers, "")}, nil
+	case ObjectKindSystem:
+		layers := make(map[string][]Layer)
+		spec, err := resolveSystemExtends(config.Catalog, obj.Spec, obj.path, "", layers)
+		if err != nil {
+			return nil, obj.locate("spec", err)
+		}
+		var explanations []Explanation
+		explainSystem(system.ApplyDefaults(spec), "", "", layers, obj.path, &explanations)
+		return explanations, nil
+	case ObjectKindOverlay:
+		return nil, obj.locate("kind", ErrExplainOverlay)
+	case ObjectKindBlueprint, ObjectKindInstance:
+		return nil, obj.locate("kind", ErrExplainBlueprint)
+	default:
+		return nil, obj.locate("kind", fmt.Errorf("invalid kind"))
+	}
+}
+
+// explainSystem adds the explanation of every block of a System spec found at the given path in
+// the spec, and of its sub-systems. parent is the path of the System tree holding the System.
+func explainSystem(spec interface{}, at, parent string, layers map[string][]Layer, file string, explanations *[]Explanation) {
+	values, ok := spec.(map[string]interface{})
+	if !ok {
+		return
+	}
+	treePath := path.Join(parent, stringField(values, "name"))
+
+	if _, blocks, ok := utils.LookupKey(values, "blocks"); ok {
+		list, _ := blocks.([]interface{})
+		for i, b := range list {
+			blockAt := utils.JoinPath(at, fmt.Sprintf("blocks[%d]", i))
+			blockValues, _ := b.(map[string]interface{})
+			*explanations = append(*explanations, explain(treePath, blockValues, layers[blockAt], file))
+		}
+	}
+	if _, systems, ok := utils.LookupKey(values, "systems"); ok {
+		list, _ := systems.([]interface{})
+		for i, s := range list {
+			explainSystem(s, utils.JoinPath(at, fmt.Sprintf("systems[%d]", i)), treePath, layers, file, explanations)
+		}
+	}
+}
+
+// explain returns the explanation of a block spec of the System at treePath, merged from layers.
+// Fields set by none of the layers are attributed to defaultsFile.
+func explain(treePath string, values map[string]interface{}, layers []Layer, defaultsFile string) Explanation {
+	e := Explanation{Block: path.Join(treePath, stringField(values, "name"))}
+	for _, layer := range layers {
+		e.Files = append(e.Files, displayPaths(layer.File)[0])
+	}
+
+	layerFields := make([]map[string]interface{}, len(layers))
+	for i, layer := range layers {
+		layerFields[i] = flattenSpec(layer.Spec)
+	}
+
+	fields := flattenSpec(values)
+	paths := make([]string, 0, len(fields))
+	for p := range fields {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		origin := FieldOrigin{Path: p, Value: fields[p], File: defaultsFile}
+		for i := len(layers) - 1; i >= 0; i-- {
+			if _, ok := layerFields[i][p]; ok {
+				origin.File = e.Files[i]
+				break
+			}
+		}
+		e.Fields = append(e.Fields, origin)
+	}
+	return e
+}
+
+// flattenSpec returns the leaves of a block spec by path. Maps are walked, except explicit inputs,
+// and hooks and dependencies are identified by name like block.MergeSpec does.
+func flattenSpec(spec map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for key, value := range spec {
+		switch key {
+		case "beforeHooks", "dependencies":
+			list, ok := value.([]interface{})
+			if !ok {
+				fields[key] = value
+				continue
+			}
+			for i, v := range list {
+				entry, _ := v.(map[string]interface{})
+				id := entryName(entry)
+				if id == "" {
+					id = fmt.Sprint(i)
+				}
+				fields[fmt.Sprintf("%s[%s]", key, id)] = v
+			}
+		default:
+			flattenValue(key, value, fields)
+		}
+	}
+	return fields
+}
+
+// flattenValue adds the leaves of value, found at path, to fields.
+func flattenValue(path string, value interface{}, fields map[string]interface{}) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		fields[path] = value
+		return
+	}
+	if _, ok := block.ExplicitInputForm(m); ok {
+		fields[path] = value
+		return
+	}
+	for key, v := range m {
+		flattenValue(utils.JoinPath(path, key), v, fields)
+	}
+}
+
+// entryName returns the name of a hook or dependency, or its block or path when it has no name.
+func entryName(entry map[string]interface{}) string {
+	for _, key := range []string{"name", "block", "path"} {
+		if name := stringField(entry, key); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// stringField returns the string value of key in m, or an empty string.
+func stringField(m map[string]interface{}, key string) string {
+	_, v, _ := utils.LookupKey(m, key)
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,144 @@
+package grunter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/grunter/system"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// extendsKey is the key of the file a block extends, as written in configuration files.
+const extendsKey = "extends"
+
+// Layer is a file contributing to the fields of a block: a block file it extends, directly
+// or not, or the file defining the block.
+type Layer struct {
+	File string                 // Path of the file.
+	Spec map[string]interface{} // Fields of the block set in the file, extends left out.
+}
+
+// resolveExtends returns the block spec defined in file merged over the chain of block files it extends,
+// with block.MergeSpec, and the layers of the chain, from the furthest block file to file itself.
+// Files are referenced by path or by catalog entry. seen holds the absolute paths of the files of
+// the chain visited so far, to detect cycles.
+func resolveExtends(catalog Catalog, spec interface{}, file string, seen []string) (interface{}, []Layer, error) {
+	values, ok := spec.(map[string]interface{})
+	if !ok {
+		return spec, nil, nil
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, s := range seen {
+		if s == abs {
+			return nil, nil, utils.AtPath(extendsKey, ErrExtendsCycle(displayPaths(append(seen, abs)...)))
+		}
+	}
+	seen = append(seen, abs)
+
+	key, extends, ok := utils.LookupKey(values, extendsKey)
+	if !ok || extends == nil {
+		return values, []Layer{{File: file, Spec: values}}, nil
+	}
+	own := utils.CopyMap(values)
+	delete(own, key)
+
+	ref, ok := extends.(string)
+	if !ok || ref == "" {
+		return nil, nil, utils.AtPath(extendsKey, ErrInvalidExtends(fmt.Sprint(extends)))
+	}
+	parentPath := catalog.objectPath(ref, filepath.Dir(file))
+
+	parent, err := NewObjectFromFile(parentPath)
+	if err != nil {
+		return nil, nil, utils.AtPath(extendsKey, utils.WrapErrors(ErrExtends(ref), err))
+	}
+	if parent.Kind != ObjectKindBlock {
+		return nil, nil, utils.AtPath(extendsKey, ErrExtendsKind(ref, parent.Kind))
+	}
+
+	parentSpec, layers, err := resolveExtends(catalog, parent.Spec, parentPath, seen)
+	if err != nil {
+		return nil, nil, utils.AtPath(extendsKey, utils.WrapErrors(ErrExtends(ref), err))
+	}
+	base, ok := parentSpec.(map[string]interface{})
+	if !ok {
+		return nil, nil, utils.AtPath(extendsKey, ErrExtendsKind(ref, parent.Kind))
+	}
+	// The paths of the parent are relative to its own file.
+	if base, err = block.RebaseSpec(base, filepath.Dir(parentPath)); err != nil {
+		return nil, nil, utils.AtPath(extendsKey, utils.WrapErrors(ErrExtends(ref), parent.locate("spec", err)))
+	}
+
+	return block.MergeSpec(base, own), append(layers, Layer{File: file, Spec: own}), nil
+}
+
+// resolveSystemExtends resolves the extends of every block of a System spec defined in file, and
+// of its sub-systems. The layers of each block are added to layers, by path in the spec, such as
+// systems[0].blocks[1].
+func resolveSystemExtends(catalog Catalog, spec interface{}, file, at string, layers map[string][]Layer) (interface{}, error) {
+	values, ok := spec.(map[string]interface{})
+	if !ok {
+		return spec, nil
+	}
+	values = utils.CopyMap(values)
+	var errs utils.Errors
+
+	if key, blocks, ok := utils.LookupKey(values, "blocks"); ok {
+		if list, ok := blocks.([]interface{}); ok {
+			resolved := make([]interface{}, len(list))
+			for i, b := range list {
+				blockAt := utils.JoinPath(at, fmt.Sprintf("blocks[%d]", i))
+				var blockLayers []Layer
+				var err error
+				resolved[i], blockLayers, err = resolveExtends(catalog, b, file, nil)
+				errs = errs.Append(utils.AtPath(fmt.Sprintf("blocks[%d]", i), err))
+				layers[blockAt] = blockLayers
+			}
+			values[key] = resolved
+		}
+	}
+
+	if key, systems, ok := utils.LookupKey(values, "systems"); ok {
+		if list, ok := systems.([]interface{}); ok {
+			resolved := make([]interface{}, len(list))
+			for i, s := range list {
+				systemField := fmt.Sprintf("systems[%d]", i)
+				var err error
+				resolved[i], err = resolveSystemExtends(catalog, s, file, utils.JoinPath(at, systemField), layers)
+				errs = errs.Append(utils.AtPath(systemField, err))
+			}
+			values[key] = resolved
+		}
+	}
+
+	return values, errs.ErrorOrNil()
+}
+
+// rebaseSpec returns the spec of an object of the given kind, defined in a file of dir, with its
+// relative paths made absolute, so that it can be generated from another directory.
+func rebaseSpec(kind string, spec interface{}, dir string) (interface{}, error) {
+	values, ok := spec.(map[string]interface{})
+	if !ok {
+		return spec, nil
+	}
+	if kind == ObjectKindBlock {
+		return block.RebaseSpec(values, dir)
+	}
+	return system.RebaseSpec(values, dir)
+}
+
+// displayPaths returns paths relative to the working directory when possible.
+func displayPaths(paths ...string) []string {
+	cwd, _ := os.Getwd()
+	display := make([]string, len(paths))
+	for i, path := range paths {
+		display[i] = utils.RelativeTo(cwd, path)
+	}
+	return display
+}
@@ -4,97 +4,48 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"text/template"
 
 	"github.com/romainframe/grunter/pkg/utils"
 )
 
 // Gen generates a Terragrunt configuration file based on the Grunter's config.
 // It writes the generated configuration to the specified outputPath or to
-// './terragrunt.hcl' if outputPath is empty. Returns an error if the process fails.
+// './terragrunt.hcl' if outputPath is empty. Files that must be kept once created,
+// such as values.hcl, are only written when missing. Returns the paths of the
+// generated Terragrunt configurations, or an error if the process fails.
 func (g Grunter) Gen(outputPath string) ([]string, error) {
-	// Use a default path if none is specified.
-	if outputPath == "" {
-		outputPath = "./terragrunt.hcl"
-	}
-
-	// Convert the internal config to a Terragrunt configuration.
-	tgGrunts, err := g.Object.GenTerragruntGrunts(outputPath)
+	// Render every file in memory first, so that nothing is written if any of them fails.
+	files, err := g.Render(outputPath)
 	if err != nil {
-		return nil, fmt.Errorf("could not convert config to terragrunt config: %w", err)
+		return nil, err
 	}
 
 	generatedFiles := []string{}
-
-	for path, tgGrunt := range tgGrunts {
-		if filepath.Ext(path) == "" {
-			if !utils.DoesFileOrDirExists(path) {
-				err := os.MkdirAll(path, os.ModePerm)
-				if err != nil {
-					return nil, fmt.Errorf("could not create output directory: %w", err)
-				}
-			}
-
-			valuesPath := filepath.Join(path, "values.hcl")
-			if !utils.DoesFileOrDirExists(valuesPath) {
-				valuesTmpl, err := template.New("values").Parse(g.valuesTemplates)
-				if err != nil {
-					return nil, fmt.Errorf("could not parse values template: %w", err)
-				}
-
-				// Touch a values.hcl in the folder
-				valuesFile, err := os.Create(valuesPath)
-				if err != nil {
-					return nil, fmt.Errorf("could not create values file: %w", err)
-				}
-				defer func() {
-					if valuesFile != nil {
-						valuesFile.Close() // Ensure file closure after writing.
-					}
-				}()
-
-				err = valuesTmpl.Execute(valuesFile, tgGrunt.GetDefaultValues())
-				if err != nil {
-					return nil, fmt.Errorf("could not execute values template: %w", err)
-				}
-
-				if valuesFile != nil {
-					valuesFile.Close() // Ensure file closure after writing.
-				}
-			}
-
-			// Create or overwrite the Terragrunt configuration file.
-			path = filepath.Join(path, outputPath)
+	for _, file := range files {
+		if file.KeepExisting && utils.DoesFileOrDirExists(file.Path) {
+			continue
 		}
 
-		// Prepare the Terragrunt template.
-		tmpl, err := template.New("terragrunt").Parse(g.terragruntTemplate)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse terragrunt template: %w", err)
+		// Create the output directory if needed.
+		if dir := filepath.Dir(file.Path); !utils.DoesFileOrDirExists(dir) {
+			if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+				return nil, fmt.Errorf("could not create output directory: %w", err)
+			}
 		}
 
-		// Create or overwrite the Terragrunt configuration file.
-		outputFile, err := os.Create(path)
-		if err != nil {
-			return nil, fmt.Errorf("could not create output file: %w", err)
+		// Create or overwrite the file.
+		if err := os.WriteFile(file.Path, file.Content, 0o644); err != nil {
+			return nil, fmt.Errorf("could not write output file: %w", err)
 		}
-		defer func() {
-			if outputFile != nil {
-				outputFile.Close() // Ensure file closure after writing.
-			}
-		}()
 
-		// Execute the template and write the output to the file.
-		err = tmpl.Execute(outputFile, tgGrunt)
-		if err != nil {
-			return nil, fmt.Errorf("could not execute template: %w", err)
+		// Let the searches of the next objects find the file.
+		if err := utils.AddToIndexes(file.Path); err != nil {
+			return nil, fmt.Errorf("could not index output file: %w", err)
 		}
 
-		if outputFile != nil {
-			outputFile.Close() // Ensure file closure after writing.
+		if !file.KeepExisting {
+			generatedFiles = append(generatedFiles, file.Path)
 		}
-
-		generatedFiles = append(generatedFiles, path)
 	}
 
 	return generatedFiles, nil
@@ -0,0 +1,187 @@
+package grunter
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/terragrunt"
+)
+
+// Unit is a Terragrunt configuration generated by Grunter: a node of the dependency graph.
+type Unit struct {
+	Dir          string            // Output directory of the configuration, relative to the working directory.
+	OutputDir    string            // Directory the configurations of the object are generated relative to.
+	Config       terragrunt.Config // Generated configuration.
+	Dependencies []string          // Absolute directories of the dependencies that can be resolved statically.
+}
+
+// Graph is the dependency graph of a set of units. Units depend on each other through the
+// config_path of their dependencies; dependencies on directories that are not units of the
+// graph are external and are not edges.
+type Graph struct {
+	units map[string]Unit // Units by absolute directory.
+}
+
+// NewGraph returns the graph of the given Terragrunt configurations, keyed by output path relative
+// to outputDir, as returned by Object.GenTerragruntGrunts. dirs tells whether the output paths are
+// directories rather than files.
+func NewGraph(outputDir string, tgGrunts map[string]terragrunt.Config, dirs bool) (Graph, error) {
+	graph := Graph{units: make(map[string]Unit, len(tgGrunts))}
+	for _, path := range sortedPaths(tgGrunts) {
+		dir := filepath.Join(outputDir, configDir(path, dirs))
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return graph, err
+		}
+
+		unit := Unit{Dir: dir, OutputDir: outputDir, Config: tgGrunts[path]}
+		for _, dep := range unit.Config.Dependencies {
+			if depDir, ok := resolveConfigPath(abs, dep.ConfigPath); ok {
+				unit.Dependencies = append(unit.Dependencies, depDir)
+			}
+		}
+		graph.units[abs] = unit
+	}
+	return graph, nil
+}
+
+// resolveConfigPath returns the absolute directory designated by the config_path of a dependency
+// of the unit at dir. Paths starting with get_repo_root() are resolved from env.GRUNT_REPO_ROOT;
+// other interpolations cannot be resolved statically.
+func resolveConfigPath(dir, configPath string) (string, bool) {
+	if rest, ok := strings.CutPrefix(configPath, "${get_repo_root()}"); ok && env.GRUNT_REPO_ROOT != "" {
+		configPath = env.GRUNT_REPO_ROOT + rest
+	}
+	if strings.Contains(configPath, "${") {
+		return "", false
+	}
+	if !filepath.IsAbs(configPath) {
+		configPath = filepath.Join(dir, configPath)
+	}
+	return filepath.Clean(configPath), true
+}
+
+// Merge returns a graph holding the units of g and other, so that dependencies between them become edges.
+func (g Graph) Merge(other Graph) Graph {
+	merged := Graph{units: make(map[string]Unit, len(g.units)+len(other.units))}
+	for abs, unit := range g.units {
+		merged.units[abs] = unit
+	}
+	for abs, unit := range other.units {
+		merged.units[abs] = unit
+	}
+	return merged
+}
+
+// Units returns the units of the graph, sorted by directory.
+func (g Graph) Units() []Unit {
+	units := make([]Unit, 0, len(g.units))
+	for _, abs := range g.sortedKeys() {
+		units = append(units, g.units[abs])
+	}
+	return units
+}
+
+// DependsOn returns the directories of the units of the graph that unit depends on, sorted.
+func (g Graph) DependsOn(unit Unit) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dep := range unit.Dependencies {
+		if target, ok := g.units[dep]; ok && !seen[dep] {
+			seen[dep] = true
+			dirs = append(dirs, target.Dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// TopologicalOrder returns the directories of the units, every unit after the units it depends on.
+// Independent units are ordered by directory, so that the order is stable. A dependency cycle is
+// reported with its full path.
+func (g Graph) TopologicalOrder() ([]string, error) {
+	keys, err := g.topologicalKeys()
+	if err != nil {
+		return nil, err
+	}
+	order := make([]string, len(keys))
+	for i, abs := range keys {
+		order[i] = g.units[abs].Dir
+	}
+	return order, nil
+}
+
+// topologicalKeys is TopologicalOrder with the absolute directories the units are keyed by.
+func (g Graph) topologicalKeys() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(g.units))
+	order := make([]string, 0, len(g.units))
+	var stack []string
+
+	var visit func(abs string) error
+	visit = func(abs string) error {
+		switch state[abs] {
+		case visited:
+			return nil
+		case visiting:
+			return ErrDependencyCycle(g.cyclePath(stack, abs))
+		}
+
+		state[abs] = visiting
+		stack = append(stack, abs)
+		deps := append([]string{}, g.units[abs].Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, ok := g.units[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[abs] = visited
+		order = append(order, abs)
+		return nil
+	}
+
+	for _, abs := range g.sortedKeys() {
+		if err := visit(abs); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// cyclePath returns the directories of the units of the cycle closed by abs, given the stack of
+// units being visited.
+func (g Graph) cyclePath(stack []string, abs string) []string {
+	start := 0
+	for i, s := range stack {
+		if s == abs {
+			start = i
+			break
+		}
+	}
+	path := make([]string, 0, len(stack)-start+1)
+	for _, s := range stack[start:] {
+		path = append(path, g.units[s].Dir)
+	}
+	return append(path, g.units[abs].Dir)
+}
+
+// sortedKeys returns the absolute directories of the units, in lexical order.
+func (g Graph) sortedKeys() []string {
+	keys := make([]string, 0, len(g.units))
+	for abs := range g.units {
+		keys = append(keys, abs)
+	}
+	sort.Strings(keys)
+	return keys
+}
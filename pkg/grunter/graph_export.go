@@ -0,0 +1,217 @@
+package grunter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Graph export formats.
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatJSON    = "json"
+)
+
+// GraphExport is a dependency graph ready to be written. Edges go from a unit to the units it depends on.
+// Order lists the nodes that are not external in an order they can be applied in, every node
+// after the nodes its first unit depends on (see Graph.TopologicalOrder).
+type GraphExport struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+	Order []string    `json:"order"`
+}
+
+// GraphNode is a node of an exported graph: a unit, a system when collapsed, or an external
+// dependency that is not generated along with the graph.
+type GraphNode struct {
+	ID       string            `json:"id"`                 // Directory of the unit, or of the system when collapsed.
+	Name     string            `json:"name,omitempty"`     // Normalized name of the block.
+	System   string            `json:"system,omitempty"`   // Path of the system holding the block.
+	Template string            `json:"template,omitempty"` // Template of the block.
+	Metadata map[string]string `json:"metadata,omitempty"` // Metadata of the block.
+	Units    []string          `json:"units,omitempty"`    // Directories of the units of a collapsed system.
+	External bool              `json:"external,omitempty"` // Set for dependencies outside of the graph.
+}
+
+// GraphEdge is a dependency of the From node on the To node.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Export returns the graph ready to be written. When collapse is set, the units of a system are
+// merged into a single node and only the dependencies between systems are kept. A dependency
+// cycle, possibly between units of several objects merged together, is an error.
+func (g Graph) Export(collapse bool) (GraphExport, error) {
+	cwd, _ := os.Getwd()
+	order, err := g.topologicalKeys()
+	if err != nil {
+		return GraphExport{}, err
+	}
+
+	nodes := make(map[string]*GraphNode)
+	edges := make(map[GraphEdge]bool)
+
+	// nodeID returns the node a unit belongs to.
+	nodeID := func(unit Unit) string {
+		if collapse && unit.Config.Origin.System != "" {
+			return filepath.Join(unit.OutputDir, unit.Config.Origin.System)
+		}
+		return unit.Dir
+	}
+
+	for _, abs := range g.sortedKeys() {
+		unit := g.units[abs]
+		id := nodeID(unit)
+		node, ok := nodes[id]
+		if !ok {
+			node = &GraphNode{ID: id, System: unit.Config.Origin.System}
+			nodes[id] = node
+		}
+		if collapse && unit.Config.Origin.System != "" {
+			node.Units = append(node.Units, unit.Dir)
+		} else {
+			node.Name = unit.Config.Origin.Name
+			node.Template = unit.Config.Origin.Template
+			node.Metadata = unit.Config.Origin.Metadata
+		}
+
+		for _, dep := range unit.Dependencies {
+			to := ""
+			if target, ok := g.units[dep]; ok {
+				to = nodeID(target)
+			} else {
+				to = utils.RelativeTo(cwd, dep)
+				if _, ok := nodes[to]; !ok {
+					nodes[to] = &GraphNode{ID: to, External: true}
+				}
+			}
+			if to != id {
+				edges[GraphEdge{From: id, To: to}] = true
+			}
+		}
+	}
+
+	export := GraphExport{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Order: []string{}}
+	for _, node := range nodes {
+		export.Nodes = append(export.Nodes, *node)
+	}
+	sort.Slice(export.Nodes, func(i, j int) bool { return export.Nodes[i].ID < export.Nodes[j].ID })
+	for edge := range edges {
+		export.Edges = append(export.Edges, edge)
+	}
+	sort.Slice(export.Edges, func(i, j int) bool {
+		if export.Edges[i].From != export.Edges[j].From {
+			return export.Edges[i].From < export.Edges[j].From
+		}
+		return export.Edges[i].To < export.Edges[j].To
+	})
+
+	// A collapsed system is applied with its first unit.
+	ordered := make(map[string]bool, len(nodes))
+	for _, abs := range order {
+		id := nodeID(g.units[abs])
+		if !ordered[id] {
+			ordered[id] = true
+			export.Order = append(export.Order, id)
+		}
+	}
+	return export, nil
+}
+
+// Write writes the graph to w in the given format: dot, mermaid or json.
+func (e GraphExport) Write(w io.Writer, format string) error {
+	switch format {
+	case GraphFormatDOT:
+		return e.writeDOT(w)
+	case GraphFormatMermaid:
+		return e.writeMermaid(w)
+	case GraphFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	default:
+		return ErrGraphFormat(format)
+	}
+}
+
+// writeDOT writes the graph in the Graphviz DOT language.
+func (e GraphExport) writeDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph grunter {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, node := range e.Nodes {
+		attrs := fmt.Sprintf("label=%s", dotQuote(strings.Join(node.labelLines(), "\n")))
+		if node.External {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+	for _, edge := range e.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart. Nodes get generated identifiers,
+// as directories are not valid Mermaid identifiers.
+func (e GraphExport) writeMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(e.Nodes))
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, node := range e.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		label := mermaidQuote(strings.Join(node.labelLines(), "<br/>"))
+		if node.External {
+			fmt.Fprintf(&sb, "  %s([%s])\n", ids[node.ID], label)
+		} else {
+			fmt.Fprintf(&sb, "  %s[%s]\n", ids[node.ID], label)
+		}
+	}
+	for _, edge := range e.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// labelLines returns the lines of the label of the node: its identifier, then its template
+// and metadata, or the number of units of a collapsed system.
+func (n GraphNode) labelLines() []string {
+	lines := []string{n.ID}
+	if n.Template != "" {
+		lines = append(lines, n.Template)
+	}
+	keys := make([]string, 0, len(n.Metadata))
+	for key := range n.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, n.Metadata[key]))
+	}
+	if len(n.Units) > 0 {
+		lines = append(lines, fmt.Sprintf("%d unit(s)", len(n.Units)))
+	}
+	return lines
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// mermaidQuote returns s as a Mermaid quoted label.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,78 @@
+package grunter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/romainframe/grunter/pkg/terragrunt"
+)
+
+func TestTopologicalOrder(t *testing.T) {
+	// config returns a configuration depending on the given sibling directories.
+	config := func(deps ...string) terragrunt.Config {
+		var c terragrunt.Config
+		for _, dep := range deps {
+			c.Dependencies = append(c.Dependencies, terragrunt.Dependency{Name: dep, ConfigPath: "../" + dep})
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		configs map[string]terragrunt.Config
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "independent units by directory",
+			configs: map[string]terragrunt.Config{"b": config(), "a": config(), "c": config()},
+			want:    []string{"out/a", "out/b", "out/c"},
+		},
+		{
+			name:    "dependencies first",
+			configs: map[string]terragrunt.Config{"app": config("db", "network"), "db": config("network"), "network": config()},
+			want:    []string{"out/network", "out/db", "out/app"},
+		},
+		{
+			name:    "external dependencies are not edges",
+			configs: map[string]terragrunt.Config{"app": config("shared"), "db": config()},
+			want:    []string{"out/app", "out/db"},
+		},
+		{
+			name:    "cycle",
+			configs: map[string]terragrunt.Config{"a": config("b"), "b": config("c"), "c": config("a")},
+			wantErr: "dependency cycle: out/a -> out/b -> out/c -> out/a",
+		},
+		{
+			name:    "cycle reached from another unit",
+			configs: map[string]terragrunt.Config{"a": config("b"), "b": config("c"), "c": config("b")},
+			wantErr: "dependency cycle: out/b -> out/c -> out/b",
+		},
+		{
+			name:    "self dependency",
+			configs: map[string]terragrunt.Config{"a": config("a")},
+			wantErr: "dependency cycle: out/a -> out/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := NewGraph("out", tt.configs, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := graph.TopologicalOrder()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("TopologicalOrder() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TopologicalOrder() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TopologicalOrder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package grunter
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/romainframe/grunter/pkg/grunter/block"
@@ -10,18 +9,39 @@ import (
 )
 
 // Grunter encapsulates the logic for generating Terragrunt configuration files.
-// It holds the path to a configuration file, a Terragrunt template, and the parsed config.
+// It holds the path to a configuration file, the terragrunt.hcl and values.hcl templates, and the parsed config.
 type Grunter struct {
-	configPath         string
-	terragruntTemplate string
-	valuesTemplates    string
-	Object             Object
+	configPath          string
+	terragruntTemplates []string // Template texts parsed over the built-in terragrunt.hcl template, in order.
+	valuesTemplates     string
+	outputDir           string
+	usedParameters      []string // Names of the parameter values of the Config taken by a Blueprint.
+	Object              Object
 }
 
-// NewGrunter creates and initializes a Grunter instance.
+// UsedParameters returns the sorted names of the parameter values of the Config the Grunter was
+// created with that a Blueprint of its object declares.
+func (g Grunter) UsedParameters() []string {
+	return g.usedParameters
+}
+
+// WithOutputDir returns a copy of the Grunter that writes its outputs relative to dir
+// instead of the current working directory.
+func (g Grunter) WithOutputDir(dir string) Grunter {
+	g.outputDir = dir
+	return g
+}
+
+// NewGrunter creates and initializes a Grunter instance with the built-in settings.
 // It verifies the existence of the config file, parses it, and prepares the Grunter.
 // Returns an error if the config file doesn't exist or cannot be parsed.
 func New(configPath string, extraBuilders ...block.GruntBuilder) (Grunter, error) {
+	return NewWithConfig(configPath, DefaultConfig(), extraBuilders...)
+}
+
+// NewWithConfig is New with the given settings. The extra builders are applied to every block
+// after the ones of config.
+func NewWithConfig(configPath string, config Config, extraBuilders ...block.GruntBuilder) (Grunter, error) {
 	var g Grunter
 
 	// Check if the config file exists.
@@ -34,20 +54,60 @@ func New(configPath string, extraBuilders ...block.GruntBuilder) (Grunter, error
 	// Parse the configuration file.
 	obj, err := NewObjectFromFile(configPath)
 	if err != nil {
-		return g, fmt.Errorf("could not parse config file: %w", err)
+		return g, utils.WrapErrors(ErrParseConfig, err)
 	}
 	// Process the config for any post-unmarshal setup or validation.
+	config.Builders = append(append([]block.GruntBuilder{}, config.Builders...), extraBuilders...)
+	obj.config = config
+	obj.parameters = newParameterUse()
+	parameters := obj.parameters
 	obj, err = obj.Build()
+
+	// The parameter values of the config are not found in a file: their problems are reported
+	// first, without position, as the object may fail because of them.
+	if err := parameters.err(config); err != nil {
+		return g, err
+	}
 	if err != nil {
 		return g, err
 	}
 
 	// Initialize and return a Grunter with the parsed config.
 	g = Grunter{
-		configPath:         configPath,
-		terragruntTemplate: terragrunt.DefaultTerragruntTemplate,
-		valuesTemplates:    terragrunt.DefaultValuesTemplate,
-		Object:             obj,
+		configPath:      configPath,
+		valuesTemplates: terragrunt.DefaultValuesTemplate,
+		usedParameters:  parameters.usedNames(),
+		Object:          obj,
+	}
+	return g.WithTemplates(config.Templates)
+}
+
+// WithTemplates returns a copy of the Grunter rendering its outputs with the given template files.
+// The terragrunt.hcl template is parsed over the current one, so that it may only redefine some
+// partials (see terragrunt.Partials). The values.hcl template replaces the current one.
+func (g Grunter) WithTemplates(t terragrunt.Templates) (Grunter, error) {
+	if t.Terragrunt != "" {
+		text, err := readTemplate(t.Terragrunt)
+		if err != nil {
+			return g, err
+		}
+		g.terragruntTemplates = append(append([]string{}, g.terragruntTemplates...), text)
+	}
+	if t.Values != "" {
+		text, err := readTemplate(t.Values)
+		if err != nil {
+			return g, err
+		}
+		g.valuesTemplates = text
 	}
 	return g, nil
 }
+
+// readTemplate returns the content of the template file at path.
+func readTemplate(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", utils.WrapError(ErrReadTemplate(path), err)
+	}
+	return string(content), nil
+}
@@ -3,19 +3,33 @@ package grunter
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
-	"github.com/mitchellh/mapstructure"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 
+	"github.com/romainframe/grunter/pkg/env"
 	"github.com/romainframe/grunter/pkg/grunter/block"
 	"github.com/romainframe/grunter/pkg/grunter/system"
+	"github.com/romainframe/grunter/pkg/utils"
 )
 
 const (
-	ObjectKindBlock  = "Block"
-	ObjectKindSystem = "System"
+	ObjectKindBlock   = "Block"
+	ObjectKindSystem  = "System"
+	ObjectKindOverlay = "Overlay"
+
+	ObjectKindBlueprint = "Blueprint"
+	ObjectKindInstance  = "Instance"
+)
+
+const (
+	// ApiVersionV1 is the original API version, decoded leniently: unknown keys are ignored.
+	ApiVersionV1 = "v1"
+	// ApiVersionV2 is decoded strictly: unknown keys are rejected.
+	ApiVersionV2 = "v2"
 )
 
 // Object represents a Grunter object with an API version, kind, metadata, and spec.
@@ -25,8 +39,13 @@ type Object struct {
 	Metadata   map[string]string `yaml:"metadata"`
 	Spec       interface{}       `yaml:"spec"`
 
-	block  block.Block
-	system system.System
+	blocks     []block.Block // Blocks of a Block object: the block itself, or one block per cell of its matrix.
+	system     system.System
+	dir        string
+	path       string
+	positions  positions
+	config     Config        // Settings the object is built and rendered with, passed on to the objects it refers to.
+	parameters *parameterUse // Use of the parameter values of config, shared with the objects it refers to.
 }
 
 func NewObjectFromFile(objectPath string) (Object, error) {
@@ -37,40 +56,83 @@ func NewObjectFromFile(objectPath string) (Object, error) {
 	}
 
 	// Determine the file extension to decide on the unmarshalling method.
+	// The position of every field is indexed along the way to locate errors.
 	ext := filepath.Ext(objectPath)
 	var object Object
+	var raw map[string]interface{}
 
 	switch ext {
 	case ".json":
-		if err := json.Unmarshal(fileContents, &object); err != nil {
+		pos, err := jsonPositions(objectPath, fileContents)
+		if err != nil {
 			return Object{}, err // Return an error if the JSON is invalid.
 		}
+		if err := json.Unmarshal(fileContents, &object); err != nil {
+			return Object{}, jsonSyntaxError(objectPath, fileContents, err)
+		}
+		if err := json.Unmarshal(fileContents, &raw); err != nil {
+			return Object{}, jsonSyntaxError(objectPath, fileContents, err)
+		}
+		object.positions = pos
 	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(fileContents, &object); err != nil {
-			return Object{}, err // Return an error if the YAML is invalid.
+		var root yaml.Node
+		if err := yaml.Unmarshal(fileContents, &root); err != nil {
+			return Object{}, &utils.FieldError{Pos: utils.Pos{File: objectPath}, Err: err} // Return an error if the YAML is invalid.
+		}
+		if err := root.Decode(&object); err != nil {
+			return Object{}, &utils.FieldError{Pos: utils.Pos{File: objectPath}, Err: err}
+		}
+		if err := root.Decode(&raw); err != nil {
+			return Object{}, &utils.FieldError{Pos: utils.Pos{File: objectPath}, Err: err}
 		}
+		object.positions = yamlPositions(objectPath, &root)
 	default:
 		return Object{}, errors.New("unsupported file type")
 	}
 
+	// Relative paths of the object are resolved from its directory.
+	object.dir = filepath.Dir(objectPath)
+	object.path = objectPath
+
 	if object.ApiVersion == "" {
-		object.ApiVersion = "v1" // Set the default API version.
+		object.ApiVersion = ApiVersionV1 // Set the default API version.
 	}
 
-	if err := object.isValidKind(); err != nil {
-		return Object{}, err // Return an error if the kind is invalid.
+	// Collect every problem with the object envelope before giving up.
+	var errs utils.Errors
+	errs = errs.Append(utils.AtPath("apiVersion", object.isValidApiVersion())) // Record an error if the API version is unknown.
+	if object.strict() {
+		errs = errs.Append(utils.UnknownFields(raw, reflect.TypeOf(object))) // Record an error for every unknown key.
 	}
-
+	errs = errs.Append(utils.AtPath("kind", object.isValidKind())) // Record an error if the kind is invalid.
 	if object.Spec == nil {
-		return Object{}, errors.New("spec is required") // Return an error if the spec is missing.
+		errs = errs.Append(utils.AtPath("spec", errors.New("spec is required"))) // Record an error if the spec is missing.
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return Object{}, inDocumentOrder(object.positions.locate(err))
 	}
 
 	return object, nil // Return the fully initialized Object.
 }
 
+func (o Object) isValidApiVersion() error {
+	switch o.ApiVersion {
+	case ApiVersionV1, ApiVersionV2:
+		return nil
+	default:
+		return fmt.Errorf("invalid apiVersion '%s'", o.ApiVersion)
+	}
+}
+
+// strict reports whether the object must be decoded strictly, rejecting unknown keys.
+// It is the default from ApiVersionV2 on, and can be forced for older objects.
+func (o Object) strict() bool {
+	return env.GRUNT_STRICT || o.ApiVersion != ApiVersionV1
+}
+
 func (o Object) isValidKind() error {
 	switch o.Kind {
-	case ObjectKindBlock, ObjectKindSystem:
+	case ObjectKindBlock, ObjectKindSystem, ObjectKindOverlay, ObjectKindBlueprint, ObjectKindInstance:
 		return nil
 	default:
 		return errors.New("invalid kind")
@@ -83,40 +145,90 @@ func (o Object) Build() (Object, error) {
 		return o.buildBlock()
 	case ObjectKindSystem:
 		return o.buildSystem()
+	case ObjectKindOverlay:
+		return o.buildOverlay()
+	case ObjectKindBlueprint:
+		return o.buildBlueprint()
+	case ObjectKindInstance:
+		return o.buildInstance()
 	default:
-		return Object{}, errors.New("invalid kind")
+		return Object{}, o.locate("kind", errors.New("invalid kind"))
 	}
 }
 
+// locate attaches the position of the field at path, or of the closest field
+// found in the document, to err and to every error it holds.
+func (o Object) locate(path string, err error) error {
+	return o.positions.locate(utils.AtPath(path, err))
+}
+
+// decode decodes input, the spec of the object or derived from it, into output, strictly if the
+// object requires it. The unknown fields are located and reported in the order of the document.
+func (o Object) decode(input, output interface{}) error {
+	if err := utils.Decode(input, output, o.strict()); err != nil {
+		return inDocumentOrder(o.locate("spec", err))
+	}
+	return nil
+}
+
 func (o Object) buildBlock() (Object, error) {
+	// Merge the spec over the block files it extends.
+	spec, _, err := resolveExtends(o.config.Catalog, o.Spec, o.path, nil)
+	if err != nil {
+		return Object{}, o.locate("spec", err)
+	}
+
 	// Unmarshal the spec into a block.
-	var block block.Block
-	if err := mapstructure.Decode(o.Spec, &block); err != nil {
+	var b block.Block
+	if err := o.decode(spec, &b); err != nil {
 		return Object{}, err
 	}
 
-	// Perform any additional setup or validation.
-	b, err := block.Build("")
+	// Fan a matrix out into one block per cell.
+	b.Dir = o.dir
+	b.StrictInputs = o.strict()
+	cells, err := b.Expand()
 	if err != nil {
-		return Object{}, err
+		return Object{}, o.locate("spec", err)
+	}
+
+	// Perform any additional setup or validation. The cells usually fail the same way: report the first one.
+	o.blocks = make([]block.Block, len(cells))
+	for i, cell := range cells {
+		built, err := cell.Build("", o.config.Builders...)
+		if err != nil {
+			return Object{}, o.locate("spec", err)
+		}
+		o.blocks[i] = built
 	}
 
 	// Return the updated Object with the block spec.
-	o.block = b
 	return o, nil
 }
 
 func (o Object) buildSystem() (Object, error) {
-	// Unmarshal the spec into a block.
+	// Merge every block over the block files it extends, then the defaults into every block.
+	spec, err := resolveSystemExtends(o.config.Catalog, o.Spec, o.path, "", map[string][]Layer{})
+	if err != nil {
+		return Object{}, o.locate("spec", err)
+	}
+
+	// Unmarshal the spec into a system.
 	var sys system.System
-	if err := mapstructure.Decode(o.Spec, &sys); err != nil {
+	if err := o.decode(system.ApplyDefaults(spec), &sys); err != nil {
 		return Object{}, err
 	}
 
 	// Perform any additional setup or validation.
+	sys.Dir = o.dir
+	sys.StrictInputs = o.strict()
+	sys.Builders = o.config.Builders
+	if sys.Layout == "" {
+		sys.Layout = o.config.Layout
+	}
 	b, err := sys.Build()
 	if err != nil {
-		return Object{}, err
+		return Object{}, o.locate("spec", err)
 	}
 
 	// Return the updated Object with the block spec.
@@ -0,0 +1,137 @@
+package grunter
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/grunter/system"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Overlay is the spec of an Overlay object: a base object and the patches applied to its spec,
+// such as a system with more replicas or an extra hook in one environment.
+type Overlay struct {
+	Base    string  `json:"base"`    // Block, System, Overlay, Blueprint or Instance file patched, or catalog entry.
+	Patches []Patch `json:"patches"` // Patches applied to the spec of the base object, in order.
+}
+
+// Patch is a change applied by an Overlay: either a strategic merge patch, merged over the spec
+// of the base object with block.MergeSpec or system.MergeSpec, or a JSON Patch operation (RFC 6902)
+// whose paths are relative to the spec.
+type Patch struct {
+	Merge map[string]interface{} `json:"merge"` // Strategic merge patch.
+	Op    string                 `json:"op"`    // JSON Patch operation: add, remove, replace, move, copy or test.
+	Path  string                 `json:"path"`  // JSON Pointer the operation applies to, such as /blocks/0/inputs/replicas.
+	From  string                 `json:"from"`  // JSON Pointer of the source of move and copy.
+	Value interface{}            `json:"value"` // Value of add, replace and test.
+}
+
+// buildOverlay patches the base object of the Overlay and builds the result like any other object.
+func (o Object) buildOverlay() (Object, error) {
+	patched, err := o.resolveOverlay(nil)
+	if err != nil {
+		return Object{}, err
+	}
+	return patched.Build()
+}
+
+// resolveOverlay returns the base object of the Overlay with its patches applied. The result is
+// generated relative to the directory of the Overlay. seen holds the absolute paths of the
+// Overlays visited so far, to detect cycles.
+func (o Object) resolveOverlay(seen []string) (Object, error) {
+	var overlay Overlay
+	if err := o.decode(o.Spec, &overlay); err != nil {
+		return Object{}, err
+	}
+	if overlay.Base == "" {
+		return Object{}, o.locate("spec.base", ErrOverlayBaseRequired)
+	}
+
+	abs, err := filepath.Abs(o.path)
+	if err != nil {
+		return Object{}, err
+	}
+	for _, s := range seen {
+		if s == abs {
+			return Object{}, o.locate("spec.base", ErrOverlayCycle(displayPaths(append(seen, abs)...)))
+		}
+	}
+	seen = append(seen, abs)
+
+	base, err := NewObjectFromFile(o.config.Catalog.objectPath(overlay.Base, o.dir))
+	if err != nil {
+		return Object{}, o.locate("spec.base", utils.WrapErrors(ErrOverlayBase(overlay.Base), err))
+	}
+	base.config = o.config
+	base.parameters = o.parameters
+	switch base.Kind {
+	case ObjectKindOverlay:
+		base, err = base.resolveOverlay(seen)
+	case ObjectKindInstance:
+		base, err = base.resolveInstance()
+	case ObjectKindBlueprint:
+		base, err = base.resolveBlueprint()
+	}
+	if err != nil {
+		return Object{}, o.locate("spec.base", utils.WrapErrors(ErrOverlayBase(overlay.Base), err))
+	}
+
+	// The blocks of the base object extend files relative to the base object, and its relative
+	// paths are resolved from it before the result moves to the directory of the Overlay.
+	var spec interface{}
+	switch base.Kind {
+	case ObjectKindBlock:
+		spec, _, err = resolveExtends(o.config.Catalog, base.Spec, base.path, nil)
+	default:
+		spec, err = resolveSystemExtends(o.config.Catalog, base.Spec, base.path, "", map[string][]Layer{})
+	}
+	if err == nil {
+		spec, err = rebaseSpec(base.Kind, spec, base.dir)
+	}
+	if err != nil {
+		return Object{}, o.locate("spec.base", utils.WrapErrors(ErrOverlayBase(overlay.Base), base.locate("spec", err)))
+	}
+
+	var errs utils.Errors
+	for i, patch := range overlay.Patches {
+		patched, err := patch.apply(base.Kind, spec)
+		if err != nil {
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("spec.patches[%d]", i), err))
+			continue
+		}
+		spec = patched
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return Object{}, o.positions.locate(err)
+	}
+
+	// The patched object is decoded strictly if either the base or the Overlay requires it.
+	if o.strict() && !base.strict() {
+		base.ApiVersion = ApiVersionV2
+	}
+	base.Spec = spec
+	base.dir = o.dir
+	base.path = o.path
+	base.positions = o.positions
+	return base, nil
+}
+
+// apply returns spec, the spec of an object of the given kind, with the patch applied.
+func (p Patch) apply(kind string, spec interface{}) (interface{}, error) {
+	if (p.Merge != nil) == (p.Op != "") {
+		return nil, ErrInvalidPatch
+	}
+	if p.Op != "" {
+		return utils.ApplyPatch(spec, utils.PatchOperation{Op: p.Op, Path: p.Path, From: p.From, Value: p.Value})
+	}
+
+	base, ok := spec.(map[string]interface{})
+	if !ok {
+		return p.Merge, nil
+	}
+	if kind == ObjectKindBlock {
+		return block.MergeSpec(base, p.Merge), nil
+	}
+	return system.MergeSpec(base, p.Merge), nil
+}
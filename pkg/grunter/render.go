@@ -0,0 +1,145 @@
+package grunter
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/terragrunt"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// File is a file generated by Grunter, rendered in memory.
+type File struct {
+	Path    string // Path of the file, relative to the working directory.
+	Content []byte // Rendered content of the file.
+	// KeepExisting is set for files that are only created when missing, such as values.hcl,
+	// and never overwritten afterwards.
+	KeepExisting bool
+}
+
+// Graph converts the Grunter's object to Terragrunt configurations and returns their dependency graph.
+func (g Grunter) Graph(outputPath string) (Graph, error) {
+	if outputPath == "" {
+		outputPath = "./terragrunt.hcl"
+	}
+	tgGrunts, err := g.Object.GenTerragruntGrunts(g.outputDir, outputPath)
+	if err != nil {
+		return Graph{}, utils.WrapErrors(ErrConvertConfig, err)
+	}
+	return NewGraph(g.outputDir, tgGrunts, g.Object.outputsAreDirs(outputPath))
+}
+
+// Render converts the Grunter's object to Terragrunt configurations and renders every file
+// Gen would write, without touching the filesystem. Files are returned in a stable order and
+// every problem found is returned, not only the first one.
+func (g Grunter) Render(outputPath string) ([]File, error) {
+	// Use a default path if none is specified.
+	if outputPath == "" {
+		outputPath = "./terragrunt.hcl"
+	}
+
+	// Convert the internal config to Terragrunt configurations.
+	tgGrunts, err := g.Object.GenTerragruntGrunts(g.outputDir, outputPath)
+	if err != nil {
+		return nil, utils.WrapErrors(ErrConvertConfig, err)
+	}
+
+	// Refuse configurations that terragrunt run-all could not order.
+	graph, err := NewGraph(g.outputDir, tgGrunts, g.Object.outputsAreDirs(outputPath))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := graph.TopologicalOrder(); err != nil {
+		return nil, err
+	}
+
+	// Prepare the templates shared by every configuration.
+	tgTmpl, err := terragrunt.NewTemplate(g.terragruntTemplates...)
+	if err != nil {
+		return nil, utils.WrapError(ErrParseTemplate("terragrunt"), err)
+	}
+	valuesTmpl, err := template.New("values").Parse(g.valuesTemplates)
+	if err != nil {
+		return nil, utils.WrapError(ErrParseTemplate("values"), err)
+	}
+
+	// Render every configuration concurrently, keeping the files in a stable order.
+	paths := sortedPaths(tgGrunts)
+	rendered := make([][]File, len(paths))
+	renderErrs := make([]error, len(paths))
+	utils.Parallel(env.GRUNT_JOBS, len(paths), func(i int) {
+		rendered[i], renderErrs[i] = g.renderConfig(paths[i], outputPath, tgGrunts[paths[i]], tgTmpl, valuesTmpl)
+	})
+
+	var files []File
+	var errs utils.Errors
+	for i := range paths {
+		errs = errs.Append(renderErrs[i])
+		files = append(files, rendered[i]...)
+	}
+
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// renderConfig renders the files of one Terragrunt configuration. Paths are resolved from the
+// output directory of the Grunter. Outputs that are directories (see Object.outputsAreDirs) hold a
+// values.hcl and the configuration itself. The templates of the configuration, if any, override the given ones.
+func (g Grunter) renderConfig(path, outputPath string, tgGrunt terragrunt.Config, tgTmpl, valuesTmpl *template.Template) ([]File, error) {
+	path = filepath.Join(g.outputDir, path)
+
+	if tgGrunt.Templates != (terragrunt.Templates{}) {
+		var err error
+		if tgTmpl, valuesTmpl, err = g.configTemplates(tgGrunt.Templates, tgTmpl, valuesTmpl); err != nil {
+			return nil, err
+		}
+	}
+
+	var files []File
+	var errs utils.Errors
+	if g.Object.outputsAreDirs(outputPath) {
+		var values bytes.Buffer
+		if err := valuesTmpl.Execute(&values, tgGrunt.GetDefaultValues()); err != nil {
+			errs = errs.Append(utils.WrapError(ErrRenderConfig(filepath.Join(path, "values.hcl")), err))
+		} else {
+			files = append(files, File{Path: filepath.Join(path, "values.hcl"), Content: values.Bytes(), KeepExisting: true})
+		}
+		path = filepath.Join(path, outputPath)
+	}
+
+	// Render the Terragrunt configuration.
+	content, err := terragrunt.RenderTemplate(tgTmpl, tgGrunt)
+	if err != nil {
+		errs = errs.Append(utils.WrapError(ErrRenderConfig(path), err))
+	} else {
+		files = append(files, File{Path: path, Content: content})
+	}
+
+	return files, errs.ErrorOrNil()
+}
+
+// configTemplates returns the templates of a configuration overriding some of the templates of the
+// Grunter. Unset templates are returned as given.
+func (g Grunter) configTemplates(t terragrunt.Templates, tgTmpl, valuesTmpl *template.Template) (*template.Template, *template.Template, error) {
+	override, err := Grunter{valuesTemplates: g.valuesTemplates}.WithTemplates(t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t.Terragrunt != "" {
+		texts := append(append([]string{}, g.terragruntTemplates...), override.terragruntTemplates...)
+		if tgTmpl, err = terragrunt.NewTemplate(texts...); err != nil {
+			return nil, nil, utils.WrapError(ErrParseTemplate(t.Terragrunt), err)
+		}
+	}
+	if t.Values != "" {
+		if valuesTmpl, err = template.New("values").Parse(override.valuesTemplates); err != nil {
+			return nil, nil, utils.WrapError(ErrParseTemplate(t.Values), err)
+		}
+	}
+	return tgTmpl, valuesTmpl, nil
+}
@@ -0,0 +1,207 @@
+package grunter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// positions maps the path of every field of a configuration document
+// (e.g. "spec.blocks[2].inputs.name") to its position in the source file.
+type positions map[string]utils.Pos
+
+// yamlPositions indexes the positions of every field of a YAML document.
+func yamlPositions(file string, root *yaml.Node) positions {
+	p := positions{}
+	p.addYAMLNode(file, "", root)
+	return p
+}
+
+// addYAMLNode records the position of node at path and walks its children.
+func (p positions) addYAMLNode(file, path string, node *yaml.Node) {
+	if _, ok := p[path]; !ok {
+		p[path] = utils.Pos{File: file, Line: node.Line, Column: node.Column}
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			p.addYAMLNode(file, path, child)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := utils.JoinPath(path, key.Value)
+			// Point at the key, which is where a reader looks for the field.
+			p[childPath] = utils.Pos{File: file, Line: key.Line, Column: key.Column}
+			p.addYAMLNode(file, childPath, value)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			p.addYAMLNode(file, fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			p.addYAMLNode(file, path, node.Alias)
+		}
+	}
+}
+
+// jsonPositions indexes the positions of every field of a JSON document.
+func jsonPositions(file string, content []byte) (positions, error) {
+	p := positions{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if err := p.addJSONValue(file, "", content, dec); err != nil {
+		return nil, jsonSyntaxError(file, content, err)
+	}
+	return p, nil
+}
+
+// addJSONValue reads the next JSON value from dec, recording the position of path and of its children.
+func (p positions) addJSONValue(file, path string, content []byte, dec *json.Decoder) error {
+	start := nextTokenOffset(content, dec.InputOffset())
+	if _, ok := p[path]; !ok {
+		p[path] = offsetPos(file, content, start)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			keyOffset := nextTokenOffset(content, dec.InputOffset())
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			childPath := utils.JoinPath(path, fmt.Sprint(key))
+			p[childPath] = offsetPos(file, content, keyOffset)
+			if err := p.addJSONValue(file, childPath, content, dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := p.addJSONValue(file, fmt.Sprintf("%s[%d]", path, i), content, dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err
+}
+
+// nextTokenOffset skips the whitespace and separators found at offset.
+func nextTokenOffset(content []byte, offset int64) int64 {
+	for offset < int64(len(content)) {
+		switch content[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// offsetPos converts a byte offset in content to a line and column position.
+func offsetPos(file string, content []byte, offset int64) utils.Pos {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return utils.Pos{File: file, Line: line, Column: column}
+}
+
+// jsonSyntaxError locates a JSON decoding error in content.
+func jsonSyntaxError(file string, content []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &utils.FieldError{Pos: offsetPos(file, content, syntaxErr.Offset), Err: err}
+	case errors.As(err, &typeErr):
+		return &utils.FieldError{Path: typeErr.Field, Pos: offsetPos(file, content, typeErr.Offset), Err: err}
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return &utils.FieldError{Pos: offsetPos(file, content, int64(len(content))), Err: io.ErrUnexpectedEOF}
+	default:
+		return &utils.FieldError{Pos: utils.Pos{File: file}, Err: err}
+	}
+}
+
+// inDocumentOrder returns err with the located errors it holds sorted by position: by line and
+// column within a file, the files keeping the order they first appear in. Errors found walking a
+// decoded document, whose keys have no order, are thus reported in the order of the document.
+func inDocumentOrder(err error) error {
+	errs, ok := err.(utils.Errors)
+	if !ok {
+		return err
+	}
+	pos := func(err error) utils.Pos {
+		if fieldErr, ok := err.(*utils.FieldError); ok {
+			return fieldErr.Pos
+		}
+		return utils.Pos{}
+	}
+	files := make(map[string]int)
+	for _, e := range errs {
+		if _, ok := files[pos(e).File]; !ok {
+			files[pos(e).File] = len(files)
+		}
+	}
+	sorted := append(utils.Errors{}, errs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := pos(sorted[i]), pos(sorted[j])
+		if a.File != b.File {
+			return files[a.File] < files[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return sorted
+}
+
+// locate resolves the position of every located error held by err. A field that is
+// missing from the document is reported at the position of the closest parent field.
+func (p positions) locate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errs, ok := err.(utils.Errors); ok {
+		located := make(utils.Errors, 0, len(errs))
+		for _, e := range errs {
+			located = append(located, p.locate(e))
+		}
+		return located
+	}
+
+	fieldErr, ok := err.(*utils.FieldError)
+	if !ok {
+		return p.locate(&utils.FieldError{Err: err})
+	}
+	if fieldErr.Pos.File != "" {
+		return fieldErr
+	}
+
+	for path, ok := fieldErr.Path, true; ok; path, ok = utils.ParentPath(path) {
+		if pos, found := p[path]; found {
+			return &utils.FieldError{Path: fieldErr.Path, Pos: pos, Err: fieldErr.Err}
+		}
+	}
+	return fieldErr
+}
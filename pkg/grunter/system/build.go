@@ -2,28 +2,96 @@ package system
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
 )
 
+// Build builds every block and sub-system of the System. Blocks are built concurrently,
+// up to env.GRUNT_JOBS at a time. Errors are collected across all of them, in the order
+// of the configuration, so that every invalid block is reported at once.
 func (s System) Build() (System, error) {
 	if len(s.Systems) == 0 && len(s.Blocks) == 0 {
-		return System{}, errors.New("no blocks defined")
+		return System{}, utils.AtPath("blocks", errors.New("no blocks defined"))
 	}
 
+	// Fan the blocks of a matrix out into one block per cell, remembering where each one is defined.
+	var errs utils.Errors
+	var blocks []block.Block
+	var indexes []int
 	for i, b := range s.Blocks {
-		block, err := b.Build(s.Name)
+		cells, err := b.Expand()
+		if err != nil {
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("blocks[%d]", i), utils.WrapErrors(ErrBuildBlock(s.Name, blockRef(i, b.Name)), err)))
+			continue
+		}
+		for range cells {
+			indexes = append(indexes, i)
+		}
+		blocks = append(blocks, cells...)
+	}
+
+	blockErrs := make([]error, len(blocks))
+	utils.Parallel(env.GRUNT_JOBS, len(blocks), func(i int) {
+		b := blocks[i]
+		b.Dir = s.Dir
+		b.StrictInputs = s.StrictInputs
+		built, err := b.Build(s.Name, s.Builders...)
 		if err != nil {
-			return System{}, err
+			blockErrs[i] = utils.AtPath(fmt.Sprintf("blocks[%d]", indexes[i]), utils.WrapErrors(ErrBuildBlock(s.Name, blockRef(indexes[i], b.Name)), err))
+			return
+		}
+		blocks[i] = built
+	})
+	s.Blocks = blocks
+	s.blockIndexes = indexes
+
+	// The cells of a block usually fail the same way: report each problem once.
+	reported := make(map[string]bool)
+	for _, err := range blockErrs {
+		for _, e := range utils.ErrorList(err) {
+			if key := e.Error(); !reported[key] {
+				reported[key] = true
+				errs = errs.Append(e)
+			}
 		}
-		s.Blocks[i] = block
 	}
 
 	for j, subSys := range s.Systems {
+		subSys.Dir = s.Dir
+		subSys.StrictInputs = s.StrictInputs
+		subSys.Builders = s.Builders
+		if subSys.Layout == "" {
+			subSys.Layout = s.Layout
+		}
 		system, err := subSys.Build()
 		if err != nil {
-			return System{}, err
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("systems[%d]", j), utils.WrapErrors(ErrBuildSystem(s.Name, systemRef(j, subSys.Name)), err)))
+			continue
 		}
 		s.Systems[j] = system
 	}
 
+	if err := errs.ErrorOrNil(); err != nil {
+		return System{}, err
+	}
 	return s, nil
 }
+
+// blockRef identifies a block by name, or by index when it has no name.
+func blockRef(index int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("blocks[%d]", index)
+	}
+	return name
+}
+
+// systemRef identifies a sub-system by name, or by index when it has no name.
+func systemRef(index int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("systems[%d]", index)
+	}
+	return name
+}
@@ -0,0 +1,91 @@
+package system
+
+import (
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// Defaults holds the settings of a System inherited by every block of the System and of its
+// sub-systems. Sub-systems may set their own defaults, merged over the ones they inherit.
+type Defaults struct {
+	Metadata     map[string]string      `json:"metadata"`     // Metadata of the blocks.
+	Locals       map[string]string      `json:"locals"`       // Local variables of the blocks.
+	Inputs       map[string]interface{} `json:"inputs"`       // Input variables of the blocks.
+	BeforeHooks  []block.BeforeHook     `json:"beforeHooks"`  // Hooks added to the blocks, by name.
+	Dependencies []block.Dependency     `json:"dependencies"` // Dependencies added to the blocks, by name.
+}
+
+// Keys of the System spec and of the sections of Defaults, as written in configuration files.
+const (
+	defaultsKey     = "defaults"
+	blocksKey       = "blocks"
+	systemsKey      = "systems"
+	metadataKey     = "metadata"
+	localsKey       = "locals"
+	inputsKey       = "inputs"
+	beforeHooksKey  = "beforeHooks"
+	dependenciesKey = "dependencies"
+)
+
+// ApplyDefaults merges the defaults of a System spec, as produced by a YAML or JSON unmarshaller,
+// into each of its blocks and sub-systems, before it is decoded. Values set by a block override the
+// defaults, following the rules of block.MergeSpec: a section set to null drops the defaults of
+// the section. Values of an unexpected type are left to the decoder to report.
+func ApplyDefaults(spec interface{}) interface{} {
+	return applyDefaults(spec, nil)
+}
+
+// applyDefaults is ApplyDefaults for a System inheriting the given defaults.
+func applyDefaults(spec interface{}, inherited map[string]interface{}) interface{} {
+	s, ok := spec.(map[string]interface{})
+	if !ok {
+		return spec
+	}
+	s = utils.CopyMap(s)
+
+	defaults := inherited
+	if key, own, ok := utils.LookupKey(s, defaultsKey); ok {
+		ownDefaults, ok := own.(map[string]interface{})
+		if !ok {
+			return s
+		}
+		defaults = mergeSections(inherited, ownDefaults)
+		s[key] = defaults
+	}
+
+	if key, blocks, ok := utils.LookupKey(s, blocksKey); ok && defaults != nil {
+		if list, ok := blocks.([]interface{}); ok {
+			merged := make([]interface{}, len(list))
+			for i, b := range list {
+				if m, ok := b.(map[string]interface{}); ok {
+					merged[i] = mergeSections(defaults, m)
+				} else {
+					merged[i] = b
+				}
+			}
+			s[key] = merged
+		}
+	}
+
+	if key, systems, ok := utils.LookupKey(s, systemsKey); ok {
+		if list, ok := systems.([]interface{}); ok {
+			merged := make([]interface{}, len(list))
+			for i, sub := range list {
+				merged[i] = applyDefaults(sub, defaults)
+			}
+			s[key] = merged
+		}
+	}
+	return s
+}
+
+// mergeSections returns values with the sections of defaults merged in with block.MergeSpec.
+func mergeSections(defaults, values map[string]interface{}) map[string]interface{} {
+	sections := make(map[string]interface{})
+	for _, section := range []string{metadataKey, localsKey, inputsKey, beforeHooksKey, dependenciesKey} {
+		if _, value, ok := utils.LookupKey(defaults, section); ok && value != nil {
+			sections[section] = value
+		}
+	}
+	return block.MergeSpec(sections, values)
+}
@@ -0,0 +1,46 @@
+package system
+
+import "fmt"
+
+// Predefined errors for system operations.
+var (
+	// ErrBuildBlock is returned when a block of the system cannot be built.
+	ErrBuildBlock = func(system, block string) error {
+		return fmt.Errorf("system '%s': failed to build block '%s'", system, block)
+	}
+
+	// ErrBuildSystem is returned when a sub-system of the system cannot be built.
+	ErrBuildSystem = func(system, subSystem string) error {
+		return fmt.Errorf("system '%s': failed to build sub-system '%s'", system, subSystem)
+	}
+
+	// ErrGenBlock is returned when the Terragrunt configuration of a block cannot be generated.
+	ErrGenBlock = func(block string) error {
+		return fmt.Errorf("failed to generate block '%s'", block)
+	}
+
+	// ErrDuplicateConfig is returned when two blocks generate the same Terragrunt configuration.
+	ErrDuplicateConfig = func(name string) error {
+		return fmt.Errorf("duplicate Terragrunt configuration name: %s", name)
+	}
+
+	// ErrInvalidLayout is returned when the output directory of a block cannot be computed from a layout.
+	ErrInvalidLayout = func(layout string) error {
+		return fmt.Errorf("invalid layout '%s'", layout)
+	}
+
+	// ErrUnknownLayoutVariable is returned when a layout holds an unknown placeholder.
+	ErrUnknownLayoutVariable = func(name string) error {
+		return fmt.Errorf("unknown variable '{{%s}}', expected one of: {{system}}, {{block}}, {{cell}}, {{metadata.<key>}}", name)
+	}
+
+	// ErrMissingLayoutMetadata is returned when a layout references a metadata the block does not set.
+	ErrMissingLayoutMetadata = func(key string) error {
+		return fmt.Errorf("metadata '%s' is not set on the block", key)
+	}
+
+	// ErrLayoutOutsideOutputDir is returned when a layout does not resolve to a sub-directory of the output directory.
+	ErrLayoutOutsideOutputDir = func(dir string) error {
+		return fmt.Errorf("'%s' is not a sub-directory of the output directory", dir)
+	}
+)
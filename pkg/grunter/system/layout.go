@@ -0,0 +1,89 @@
+package system
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// DefaultLayout is the layout of the Systems that do not set one. Nested systems produce
+// nested directories.
+const DefaultLayout = "{{system}}/{{block}}"
+
+// layoutVariableRegex matches the {{variable}} placeholders of a layout.
+var layoutVariableRegex = regexp.MustCompile(`\{\{\s*([^{}\s]*)\s*\}\}`)
+
+// layout returns the layout of the System, or DefaultLayout when it has none.
+func (s System) layout() string {
+	if s.Layout == "" {
+		return DefaultLayout
+	}
+	return s.Layout
+}
+
+// outputDir returns the output directory of a block of the System found at parent in the System tree,
+// relative to the output directory, by replacing the placeholders of the layout of the System:
+//   - {{system}}: the path of the System in the tree, such as platform/network;
+//   - {{block}}: the name of the block;
+//   - {{metadata.<key>}}: the value of a metadata of the block, which must be set;
+//   - {{cell}}: the path of the matrix cell of the block, empty for blocks without matrix.
+//
+// The path of the cell is appended to the directory of a block of a matrix when the layout uses
+// neither {{cell}} nor the metadata of every axis of the matrix. Names are normalized like block
+// names. The directory must be a sub-directory of the output directory.
+func (s System) outputDir(b block.Block, parent string) (string, error) {
+	layout := s.layout()
+	var errs utils.Errors
+	locatesCell := false
+	located := make(map[string]bool)
+	dir := layoutVariableRegex.ReplaceAllStringFunc(layout, func(placeholder string) string {
+		name := layoutVariableRegex.FindStringSubmatch(placeholder)[1]
+		switch {
+		case name == "system":
+			return block.TargetName(s.treePath(parent))
+		case name == "block":
+			return path.Base(b.Name)
+		case name == "cell":
+			locatesCell = true
+			return b.Cell
+		case strings.HasPrefix(name, "metadata."):
+			key := strings.TrimPrefix(name, "metadata.")
+			located[key] = true
+			value, ok := b.Metadata[key]
+			if !ok {
+				errs = errs.Append(ErrMissingLayoutMetadata(key))
+			}
+			return value
+		default:
+			errs = errs.Append(ErrUnknownLayoutVariable(name))
+			return placeholder
+		}
+	})
+	if err := errs.ErrorOrNil(); err != nil {
+		return "", utils.WrapErrors(ErrInvalidLayout(layout), err)
+	}
+
+	if b.Cell != "" && !locatesCell && !locatesAxes(b.Axes, located) {
+		dir = path.Join(dir, b.Cell)
+	}
+
+	// Empty variables, such as the path of an unnamed root System, leave empty segments.
+	dir = path.Clean(strings.TrimLeft(dir, "/"))
+	if dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", utils.WrapError(ErrInvalidLayout(layout), ErrLayoutOutsideOutputDir(dir))
+	}
+	return "./" + dir, nil
+}
+
+// locatesAxes reports whether every axis of a matrix is one of the located metadata.
+func locatesAxes(axes []string, located map[string]bool) bool {
+	for _, axis := range axes {
+		if !located[axis] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"strings"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// MergeSpec merges the fields of a System spec over the ones of base, both as produced by a YAML or
+// JSON unmarshaller, and returns the result. Values set in values win:
+//   - blocks and systems are merged by name: an entry is merged over the one of the same name,
+//     unless it sets $unset, which removes it, and the other entries are appended. Blocks are
+//     merged with block.MergeSpec and systems with MergeSpec;
+//   - defaults are merged with block.MergeSpec;
+//   - a field set to null is unset, and any other value replaces the one of base.
+//
+// Values of an unexpected type are left to the decoder to report.
+func MergeSpec(base, values map[string]interface{}) map[string]interface{} {
+	merged := utils.CopyMap(base)
+	for key, value := range values {
+		baseKey, baseValue, ok := utils.LookupKey(base, key)
+		if ok && baseKey != key {
+			delete(merged, baseKey)
+		}
+		switch {
+		case value == nil:
+			delete(merged, key)
+		case strings.EqualFold(key, blocksKey):
+			merged[key] = block.MergeList(baseValue, value, nameOf, block.MergeSpec)
+		case strings.EqualFold(key, systemsKey):
+			merged[key] = block.MergeList(baseValue, value, nameOf, MergeSpec)
+		case !ok || baseValue == nil:
+			merged[key] = value
+		case strings.EqualFold(key, defaultsKey):
+			merged[key] = mergeMap(baseValue, value, block.MergeSpec)
+		default:
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// mergeMap merges value over baseValue with merge when both are maps. Any other value replaces baseValue.
+func mergeMap(baseValue, value interface{}, merge func(base, values map[string]interface{}) map[string]interface{}) interface{} {
+	base, ok := baseValue.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	return merge(base, values)
+}
+
+// nameOf returns the name of a block or system spec, or an empty string.
+func nameOf(spec map[string]interface{}) string {
+	_, v, _ := utils.LookupKey(spec, "name")
+	name, _ := v.(string)
+	return name
+}
@@ -0,0 +1,52 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// RebaseSpec returns a copy of a System spec, as produced by a YAML or JSON unmarshaller, with the
+// paths relative to the file declaring it, found in dir, made absolute in its defaults, its blocks
+// and its sub-systems (see block.RebaseSpec).
+func RebaseSpec(spec map[string]interface{}, dir string) (map[string]interface{}, error) {
+	rebased := utils.CopyMap(spec)
+	var errs utils.Errors
+
+	if key, value, ok := utils.LookupKey(spec, defaultsKey); ok {
+		if defaults, ok := value.(map[string]interface{}); ok {
+			var err error
+			rebased[key], err = block.RebaseSpec(defaults, dir)
+			errs = errs.Append(utils.AtPath(key, err))
+		}
+	}
+
+	for _, listKey := range []string{blocksKey, systemsKey} {
+		key, value, ok := utils.LookupKey(spec, listKey)
+		if !ok {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		entries := make([]interface{}, len(list))
+		for i, v := range list {
+			entries[i] = v
+			entry, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var err error
+			if listKey == blocksKey {
+				entries[i], err = block.RebaseSpec(entry, dir)
+			} else {
+				entries[i], err = RebaseSpec(entry, dir)
+			}
+			errs = errs.Append(utils.AtPath(fmt.Sprintf("%s[%d]", key, i), err))
+		}
+		rebased[key] = entries
+	}
+	return rebased, errs.ErrorOrNil()
+}
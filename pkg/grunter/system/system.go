@@ -1,6 +1,8 @@
 package system
 
 import (
+	"fmt"
+
 	"github.com/romainframe/grunter/pkg/grunter/block"
 )
 
@@ -8,4 +10,33 @@ type System struct {
 	Name    string        `json:"name"`
 	Systems []System      `json:"systems"`
 	Blocks  []block.Block `json:"blocks"`
+
+	// Defaults are merged into every block and sub-system before they are built (see ApplyDefaults).
+	Defaults Defaults `json:"defaults"`
+
+	// Layout is the template of the output directory of every block, such as
+	// {{metadata.env}}/{{system}}/{{block}}. Sub-systems inherit it unless they set their own.
+	Layout string `json:"layout"`
+
+	// Dir is the directory the system is generated from. It is passed down to
+	// every block and sub-system. It is set by the caller, not decoded.
+	Dir string `json:"-" mapstructure:"-"`
+	// StrictInputs is passed down to every block and sub-system (see block.Block). It is set by
+	// the caller, not decoded.
+	StrictInputs bool `json:"-" mapstructure:"-"`
+	// Builders are the extra builders every block and sub-system is built with (see block.Build).
+	// They are set by the caller, not decoded.
+	Builders []block.GruntBuilder `json:"-" mapstructure:"-"`
+
+	// blockIndexes holds the index in the configuration of each block, once the blocks of a matrix are
+	// fanned out into one block per cell by Build.
+	blockIndexes []int
+}
+
+// blockField returns the path of the i-th block of the System in the configuration.
+func (s System) blockField(i int) string {
+	if i < len(s.blockIndexes) {
+		i = s.blockIndexes[i]
+	}
+	return fmt.Sprintf("blocks[%d]", i)
 }
@@ -2,41 +2,115 @@ package system
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
 
+	"github.com/romainframe/grunter/pkg/env"
+	"github.com/romainframe/grunter/pkg/grunter/block"
 	"github.com/romainframe/grunter/pkg/terragrunt"
+	"github.com/romainframe/grunter/pkg/utils"
 )
 
-func (s System) GenTerragruntGrunts(outputPath string) (map[string]terragrunt.Config, error) {
+// GenTerragruntGrunts generates the Terragrunt configuration of every block of the System
+// and of its sub-systems, keyed by output directory relative to outputDir. Blocks are generated
+// concurrently, up to env.GRUNT_JOBS at a time, and errors are reported in the order of the configuration.
+// Every block can depend on any other block of the System tree by its path in the tree. The locals
+// referenced but not defined are generated with resolvers.
+func (s System) GenTerragruntGrunts(outputDir, outputPath string, resolvers terragrunt.LocalResolvers) (map[string]terragrunt.Config, error) {
+	targets := make(map[string]string)
+	matrices := make(map[string][]string)
+	s.collectTargets(outputDir, "", targets, matrices)
+	return s.genTerragruntGrunts(outputDir, outputPath, "", targets, matrices, resolvers)
+}
+
+// genTerragruntGrunts is GenTerragruntGrunts for a System found at parent in the System tree,
+// with the targets and matrices of the whole tree.
+func (s System) genTerragruntGrunts(outputDir, outputPath, parent string, targets map[string]string, matrices map[string][]string, resolvers terragrunt.LocalResolvers) (map[string]terragrunt.Config, error) {
 	result := make(map[string]terragrunt.Config)
+	var errs utils.Errors
 
-	for _, block := range s.Blocks {
-		tgConfig, err := block.GenTerragruntGrunt()
+	tgConfigs := make([]terragrunt.Config, len(s.Blocks))
+	configNames := make([]string, len(s.Blocks))
+	blockErrs := make([]error, len(s.Blocks))
+	utils.Parallel(env.GRUNT_JOBS, len(s.Blocks), func(i int) {
+		b := s.Blocks[i]
+		configName, err := s.outputDir(b, parent)
 		if err != nil {
-			return nil, err
+			blockErrs[i] = err
+			return
 		}
-		basePath := "."
-		if s.Name == "" {
-			basePath = fmt.Sprintf("%s/%s", basePath, s.Name)
+		configNames[i] = configName
+		b.OutputDir = filepath.Join(outputDir, configName)
+		b.Targets = targets
+		b.Matrices = matrices
+		b.System = s.treePath(parent)
+		tgConfigs[i], blockErrs[i] = b.GenTerragruntGrunt(resolvers)
+	})
+
+	for i, block := range s.Blocks {
+		blockField := s.blockField(i)
+		if err := blockErrs[i]; err != nil {
+			errs = errs.Append(utils.AtPath(blockField, utils.WrapErrors(ErrGenBlock(block.Name), err)))
+			continue
 		}
-		tgConfigName := fmt.Sprintf("%s/%s", basePath, block.Name)
+		tgConfigName := configNames[i]
 		if _, ok := result[tgConfigName]; ok {
-			return nil, fmt.Errorf("duplicate Terragrunt configuration name: %s", tgConfigName)
+			errs = errs.Append(utils.AtPath(blockField+".name", ErrDuplicateConfig(tgConfigName)))
+			continue
 		}
-		result[tgConfigName] = tgConfig
+		result[tgConfigName] = tgConfigs[i]
 	}
 
-	for _, subSystems := range s.Systems {
-		subResult, err := subSystems.GenTerragruntGrunts(outputPath)
-		if err != nil {
-			return nil, err
+	for j, subSystems := range s.Systems {
+		systemField := fmt.Sprintf("systems[%d]", j)
+		subResult, err := subSystems.genTerragruntGrunts(outputDir, outputPath, s.treePath(parent), targets, matrices, resolvers)
+		errs = errs.Append(utils.AtPath(systemField, err))
+
+		// Merge in a stable order so that duplicates are reported deterministically.
+		names := make([]string, 0, len(subResult))
+		for k := range subResult {
+			names = append(names, k)
 		}
-		for k, v := range subResult {
+		sort.Strings(names)
+		for _, k := range names {
 			if _, ok := result[k]; ok {
-				return nil, fmt.Errorf("duplicate Terragrunt configuration name: %s", k)
+				errs = errs.Append(utils.AtPath(systemField, ErrDuplicateConfig(k)))
+				continue
 			}
-			result[k] = v
+			result[k] = subResult[k]
 		}
 	}
 
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+// treePath returns the path of the System in the System tree, given the path of its parent.
+func (s System) treePath(parent string) string {
+	return path.Join(parent, s.Name)
+}
+
+// collectTargets adds the output directory of every block of the System tree to targets, by path
+// in the tree, followed by the path of their matrix cell, if any. Block names are normalized with the
+// name of their system, which is left out here.
+// Blocks whose output directory cannot be computed are skipped; the error is reported when generating them.
+// The cells of every block of a matrix are added to matrices, by path in the tree.
+func (s System) collectTargets(outputDir, parent string, targets map[string]string, matrices map[string][]string) {
+	for _, b := range s.Blocks {
+		name := path.Join(s.treePath(parent), path.Base(b.Name))
+		if b.Cell != "" {
+			matrices[block.TargetName(name)] = append(matrices[block.TargetName(name)], b.Cell)
+		}
+		configName, err := s.outputDir(b, parent)
+		if err != nil {
+			continue
+		}
+		targets[block.TargetName(path.Join(name, b.Cell))] = filepath.Join(outputDir, configName)
+	}
+	for _, subSystem := range s.Systems {
+		subSystem.collectTargets(outputDir, s.treePath(parent), targets, matrices)
+	}
+}
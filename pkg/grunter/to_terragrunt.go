@@ -1,18 +1,87 @@
 package grunter
 
-import "github.com/romainframe/grunter/pkg/terragrunt"
+import (
+	"path"
+	"path/filepath"
+	"sort"
 
-func (o Object) GenTerragruntGrunts(outputPath string) (map[string]terragrunt.Config, error) {
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/terragrunt"
+)
 
+// GenTerragruntGrunts converts the Object to Terragrunt configurations, keyed by output path.
+// Output paths are relative to outputDir, which dependency paths are computed from.
+func (o Object) GenTerragruntGrunts(outputDir, outputPath string) (map[string]terragrunt.Config, error) {
 	switch o.Kind {
 	case ObjectKindBlock:
-		tfConfig, err := o.block.GenTerragruntGrunt()
-		if err != nil {
-			return nil, err
+		// Every cell of a matrix is written to the directory of its path, under the output path.
+		targets := make(map[string]string, len(o.blocks))
+		matrices := make(map[string][]string)
+		paths := make([]string, len(o.blocks))
+		for i, b := range o.blocks {
+			paths[i] = cellPath(outputPath, b.Cell)
+			targets[block.TargetName(path.Join(b.Name, b.Cell))] = filepath.Join(outputDir, configDir(paths[i], o.outputsAreDirs(outputPath)))
+			if b.Cell != "" {
+				matrices[block.TargetName(b.Name)] = append(matrices[block.TargetName(b.Name)], b.Cell)
+			}
+		}
+
+		tgConfigs := make(map[string]terragrunt.Config, len(o.blocks))
+		for i, b := range o.blocks {
+			b.OutputDir = targets[block.TargetName(path.Join(b.Name, b.Cell))]
+			b.Targets = targets
+			b.Matrices = matrices
+			tfConfig, err := b.GenTerragruntGrunt(o.config.Locals)
+			if err != nil {
+				return nil, o.locate("spec", err)
+			}
+			tgConfigs[paths[i]] = tfConfig
 		}
-		return map[string]terragrunt.Config{outputPath: tfConfig}, nil
+		return tgConfigs, nil
 	case ObjectKindSystem:
-		return o.system.GenTerragruntGrunts(outputPath)
+		tgConfigs, err := o.system.GenTerragruntGrunts(outputDir, outputPath, o.config.Locals)
+		if err != nil {
+			return nil, o.locate("spec", err)
+		}
+		return tgConfigs, nil
 	}
 	return nil, nil
 }
+
+// outputsAreDirs reports whether the output paths returned by GenTerragruntGrunts for outputPath are
+// directories rather than files: the ones of a System always are, the ones of a Block are when
+// outputPath has no extension. The output paths themselves are not looked at, as matrix cells and
+// layouts may hold dots.
+func (o Object) outputsAreDirs(outputPath string) bool {
+	return o.Kind == ObjectKindSystem || filepath.Ext(outputPath) == ""
+}
+
+// configDir returns the directory a Terragrunt configuration is written to, given its output path
+// and whether output paths are directories (see outputsAreDirs).
+func configDir(path string, isDir bool) string {
+	if isDir {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// cellPath returns the output path of the given matrix cell: outputPath in the directory of the cell.
+func cellPath(outputPath, cell string) string {
+	if cell == "" {
+		return outputPath
+	}
+	if filepath.Ext(outputPath) == "" {
+		return filepath.Join(outputPath, cell)
+	}
+	return filepath.Join(filepath.Dir(outputPath), cell, filepath.Base(outputPath))
+}
+
+// sortedPaths returns the output paths of the given Terragrunt configurations in a stable order.
+func sortedPaths(tgGrunts map[string]terragrunt.Config) []string {
+	paths := make([]string, 0, len(tgGrunts))
+	for path := range tgGrunts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,9 @@
+package grunter
+
+// Validate converts the Grunter's object to Terragrunt configurations and renders each
+// of them in memory, without writing anything to disk. Every problem found is returned,
+// not only the first one.
+func (g Grunter) Validate(outputPath string) error {
+	_, err := g.Render(outputPath)
+	return err
+}
@@ -0,0 +1,164 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/romainframe/grunter/pkg/grunter/block"
+	"github.com/romainframe/grunter/pkg/utils"
+)
+
+// FileName is the name of the project configuration file, looked up at the repository root.
+const FileName = ".grunter.yaml"
+
+// Predefined errors for project operations.
+var (
+	// ErrRootNotFound is returned when the repository root cannot be detected.
+	ErrRootNotFound = func(dir string) error {
+		return fmt.Errorf("could not detect the repository root from '%s': add a %s or set GRUNT_REPO_ROOT", dir, FileName)
+	}
+
+	// ErrInvalidConfig is returned when the project configuration file cannot be parsed.
+	ErrInvalidConfig = func(path string) error {
+		return fmt.Errorf("invalid project configuration '%s'", path)
+	}
+)
+
+// Config holds the settings of a repository, read from the .grunter.yaml file at its root.
+// Every setting is optional and defaults to the built-in value returned by Default.
+type Config struct {
+	Strict        bool              `yaml:"strict"`        // Reject unknown keys whatever the apiVersion.
+	Jobs          int               `yaml:"jobs"`          // Maximum number of concurrent workers.
+	Files         Files             `yaml:"files"`         // Names of the grunter object files.
+	Env           Env               `yaml:"env"`           // Names of the environment variables read by the generated locals.
+	SpecialLocals map[string]string `yaml:"specialLocals"` // Extra special locals, by name, as templates of HCL expressions.
+	DefaultLocal  string            `yaml:"defaultLocal"`  // Template of the locals without special resolver.
+	K8s           K8s               `yaml:"k8s"`           // Settings of the Kubernetes builder.
+	Layout        string            `yaml:"layout"`        // Template of the output directory of the blocks of a system.
+	Catalog       map[string]string `yaml:"catalog"`       // Object files referenced by name by extends, Overlays and Instances, relative to the repository root.
+	Templates     Templates         `yaml:"templates"`     // Output templates, relative to the repository root.
+
+	// Root is the repository root, the directory holding the configuration file. It is not decoded.
+	Root string `yaml:"-"`
+}
+
+// Files holds the names of the files looked up when no input path is given.
+type Files struct {
+	Block    string `yaml:"block"`    // Default block file name.
+	System   string `yaml:"system"`   // Default system file name.
+	Overlay  string `yaml:"overlay"`  // Default overlay file name.
+	Instance string `yaml:"instance"` // Default instance file name.
+}
+
+// Env holds the names of the environment variables read by the generated special locals.
+type Env struct {
+	Email        string `yaml:"email"`        // Variable holding the email used by hooks.
+	TemplateRoot string `yaml:"templateRoot"` // Variable holding the root of the Terraform templates.
+}
+
+// Templates holds the paths of the templates the outputs are rendered with.
+type Templates struct {
+	Terragrunt string `yaml:"terragrunt"` // terragrunt.hcl template, possibly only redefining some partials.
+	Values     string `yaml:"values"`     // values.hcl template.
+}
+
+// K8s holds the settings of the Kubernetes builder.
+type K8s struct {
+	ParentFolder string                      `yaml:"parentFolder"` // Folder holding the cluster folders.
+	ClusterFile  string                      `yaml:"clusterFile"`  // File read from the cluster folder.
+	BeforeHooks  map[string]block.BeforeHook `yaml:"beforeHooks"`  // Hooks added to Kubernetes blocks, by cloud.
+}
+
+// Config returns the settings of the Kubernetes builder (see block.NewK8sGruntBuilder).
+func (k K8s) Config() block.K8sConfig {
+	return block.K8sConfig{
+		ParentFolder: k.ParentFolder,
+		ClusterFile:  k.ClusterFile,
+		BeforeHooks:  k.BeforeHooks,
+	}
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	// The built-in hooks are copied, so that decoding a file merges into the copy.
+	k8s := block.DefaultK8sConfig()
+
+	return Config{
+		Files: Files{
+			Block:    "block.yaml",
+			System:   "system.yaml",
+			Overlay:  "overlay.yaml",
+			Instance: "instance.yaml",
+		},
+		Env: Env{
+			Email:        "TF_VAR_EMAIL",
+			TemplateRoot: "TF_VAR_TEMPLATE_ROOT",
+		},
+		SpecialLocals: map[string]string{},
+		K8s: K8s{
+			ParentFolder: k8s.ParentFolder,
+			ClusterFile:  k8s.ClusterFile,
+			BeforeHooks:  k8s.BeforeHooks,
+		},
+	}
+}
+
+// FindRoot returns the closest directory, from dir upwards, holding a .grunter.yaml file
+// or a .git directory.
+func FindRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		if utils.DoesFileOrDirExists(filepath.Join(current, FileName)) || utils.DoesFileOrDirExists(filepath.Join(current, ".git")) {
+			return current, nil
+		}
+		if filepath.Dir(current) == current {
+			return "", ErrRootNotFound(dir)
+		}
+	}
+}
+
+// Load reads the .grunter.yaml file of the repository rooted at root, if there is one,
+// on top of the built-in configuration. Unknown keys are rejected.
+func Load(root string) (Config, error) {
+	config := Default()
+	config.Root = root
+
+	path := filepath.Join(root, FileName)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, utils.WrapError(ErrInvalidConfig(path), err)
+	}
+
+	config.Templates.Terragrunt = resolvePath(root, config.Templates.Terragrunt)
+	config.Templates.Values = resolvePath(root, config.Templates.Values)
+	for name, path := range config.Catalog {
+		config.Catalog[name] = resolvePath(root, path)
+	}
+	return config, nil
+}
+
+// resolvePath returns path relative to root, or an empty path if none is set.
+func resolvePath(root, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
@@ -5,24 +5,60 @@ import "strings"
 // Config stores the configuration for a Terragrunt project, including dependencies,
 // local variables, OpenTofu configurations, and inputs.
 type Config struct {
-	Dependencies   []Dependency      `json:"dependencies"`    // List of external Terragrunt dependencies
-	LocalVariables []LocalVariable   `json:"local_variables"` // Local variables specific to the Terragrunt configuration
-	OpenTofu       OpenTofu          `json:"open_tofu"`       // Configuration for OpenTofu, a fictional feature or module
-	Inputs         map[string]string `json:"inputs"`          // Key-value pairs for Terragrunt inputs
+	Dependencies   []Dependency           `json:"dependencies"`    // List of external Terragrunt dependencies
+	LocalVariables []LocalVariable        `json:"local_variables"` // Local variables specific to the Terragrunt configuration
+	OpenTofu       OpenTofu               `json:"open_tofu"`       // Configuration for OpenTofu, a fictional feature or module
+	Inputs         map[string]interface{} `json:"inputs"`          // Terragrunt inputs: expressions as strings, or literals of any type
+	Templates      Templates              `json:"templates"`       // Templates overriding the ones of the caller, if any
+	Origin         Origin                 `json:"origin"`          // Block the configuration is generated from, not rendered
 }
 
+// Origin describes the grunter block a Terragrunt configuration is generated from.
+type Origin struct {
+	Name     string            `json:"name"`     // Normalized name of the block.
+	System   string            `json:"system"`   // Path of the system holding the block in the system tree, if any.
+	Template string            `json:"template"` // Template of the block.
+	Metadata map[string]string `json:"metadata"` // Metadata of the block.
+}
+
+// Literal is an input value always rendered as a quoted string. Interpolation sequences such
+// as ${local.name} are kept.
+type Literal string
+
+// Expression is an input value always rendered as is, as an HCL expression.
+type Expression string
+
+// GetDefaultValues returns the values.hcl locals referenced by the inputs, however deeply nested,
+// each one defaulting to its own name.
 func (c Config) GetDefaultValues() map[string]string {
 	defaults := make(map[string]string)
 	for _, value := range c.Inputs {
-		val := ""
-		if strings.HasPrefix(value, "local.values.locals") {
-			val = strings.TrimPrefix(value, "local.values.locals.")
+		WalkExpressions(value, func(value string) {
+			if val := strings.TrimPrefix(value, "local.values.locals."); val != value && val != "" {
+				defaults[val] = val
+			}
+		})
+	}
+	return defaults
+}
+
+// WalkExpressions calls fn for every string or Expression found in value, descending into lists
+// and maps. Literals are skipped.
+func WalkExpressions(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case Expression:
+		fn(string(v))
+	case []interface{}:
+		for _, elem := range v {
+			WalkExpressions(elem, fn)
 		}
-		if val != "" {
-			defaults[val] = val
+	case map[string]interface{}:
+		for _, elem := range v {
+			WalkExpressions(elem, fn)
 		}
 	}
-	return defaults
 }
 
 // Dependency defines a Terragrunt project's external dependency, including its
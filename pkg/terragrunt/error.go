@@ -5,11 +5,36 @@ import "fmt"
 // Predefined errors for local variable operations.
 var (
 
-	// ErrExtractLocalPath is returned when extracting a local variable path fails.
-	ErrExtractLocalPath = func(part string) error {
-		return fmt.Errorf("failed to extract local path from: %s", part)
+	// ErrExtractLocalPath is returned when the local variables of a value cannot be extracted.
+	ErrExtractLocalPath = func(value string) error {
+		return fmt.Errorf("failed to extract local variables from: %s", value)
+	}
+
+	// ErrResolveLocal is returned when a local variable cannot be resolved.
+	ErrResolveLocal = func(name string) error {
+		return fmt.Errorf("failed to resolve local variable '%s'", name)
+	}
+
+	// ErrInvalidSpecialLocal is returned when the template of a special local cannot be parsed.
+	ErrInvalidSpecialLocal = func(name string) error {
+		return fmt.Errorf("invalid special local '%s'", name)
 	}
 
 	// ErrValidateLocals is returned when validating local variables fails.
 	ErrValidateLocals = fmt.Errorf("failed to validate local variables")
+
+	// ErrRenderDependency is returned when a dependency cannot be rendered.
+	ErrRenderDependency = func(configPath string) error {
+		return fmt.Errorf("failed to render dependency with config path '%s': name is required", configPath)
+	}
+
+	// ErrRenderLocal is returned when a local variable name is not a valid HCL identifier.
+	ErrRenderLocal = func(name string) error {
+		return fmt.Errorf("failed to render local variable '%s': invalid identifier", name)
+	}
+
+	// ErrInvalidTemplateOutput is returned when a template does not render valid HCL.
+	ErrInvalidTemplateOutput = func(err error) error {
+		return fmt.Errorf("template rendered invalid HCL: %w", err)
+	}
 )
@@ -2,90 +2,81 @@ package terragrunt
 
 import (
 	"fmt"
-	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
 	"github.com/romainframe/grunter/pkg/utils"
 )
 
 // LocalsSearch is a structure that holds a set of local variable names extracted from strings.
 type LocalsSearch struct {
-	values map[string]struct{}
+	values    map[string]struct{}
+	context   LocalContext
+	resolvers LocalResolvers
 }
 
-// NewLocalsSearch initializes and returns a new instance of LocalsSearch.
-func NewLocalsSearch() LocalsSearch {
+// NewLocalsSearch initializes and returns a new instance of LocalsSearch. The context describes
+// the block the locals are generated for; its Name is set for each local when resolving it. The
+// locals found are resolved with resolvers, or with DefaultLocalResolvers when it is the zero value.
+func NewLocalsSearch(context LocalContext, resolvers LocalResolvers) LocalsSearch {
+	if resolvers.Special == nil && resolvers.Default == nil {
+		resolvers = DefaultLocalResolvers()
+	}
 	return LocalsSearch{
-		values: make(map[string]struct{}),
+		values:    make(map[string]struct{}),
+		context:   context,
+		resolvers: resolvers,
 	}
 }
 
-// Add processes and stores local variables found in the given strings.
-// It supports both direct local variable names and local variables embedded within strings.
+// Add processes and stores the local variables referenced by the given HCL expressions.
+// Values that are not valid expressions are read as templates (see AddTemplate).
 func (ls LocalsSearch) Add(values ...string) error {
 	for _, value := range values {
-		if err := ls.add(value); err != nil {
-			return err
+		expr, diags := hclsyntax.ParseExpression([]byte(value), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			if err := ls.AddTemplate(value); err != nil {
+				return err
+			}
+			continue
 		}
+		ls.addVariables(expr.Variables())
 	}
 	return nil
 }
 
-// add is a helper function that extracts local variables from a single string and adds them to the set.
-func (ls LocalsSearch) add(value string) error {
-	value = strings.TrimSpace(value)
-
-	// Shortcut locals
-	if strings.HasPrefix(value, "values.") {
-		v := "local.values.locals"
-		parts := strings.Split(value, ".")
-		for i, part := range parts {
-			if i > 0 && part != " " {
-				v = fmt.Sprintf("%s.%s", v, part)
-			}
+// AddTemplate processes and stores the local variables referenced by the interpolations
+// of the given unquoted template strings, such as "${local.env}-${local.name}".
+func (ls LocalsSearch) AddTemplate(values ...string) error {
+	for _, value := range values {
+		expr, diags := hclsyntax.ParseTemplate([]byte(value), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return utils.WrapError(ErrExtractLocalPath(value), diags)
 		}
-		ls.values[v] = struct{}{}
-		return nil
-	}
-
-	// Classic locals
-	if strings.HasPrefix(value, "local.") {
-		ls.values[value] = struct{}{}
-		return nil
+		ls.addVariables(expr.Variables())
 	}
+	return nil
+}
 
-	if strings.Contains(value, "local.") {
-		parts := strings.Split(value, " ")
-		for _, part := range parts {
-			if strings.Contains(part, "${local") {
-				localPath, err := extractLocalPath(part)
-				if err != nil {
-					return utils.WrapError(ErrExtractLocalPath(part), err)
-				}
-				ls.values[localPath] = struct{}{}
+// addVariables stores the local variable at the root of every local.* traversal.
+// The values.* shortcut designates the values local.
+func (ls LocalsSearch) addVariables(traversals []hcl.Traversal) {
+	for _, traversal := range traversals {
+		switch traversal.RootName() {
+		case "local":
+			if len(traversal) < 2 {
+				continue
 			}
-		}
-		return nil
-	}
-
-	// Local variable validation: a.b.d.c.d.e
-	validLocalDefRegex := regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)*$`)
-	if validLocalDefRegex.MatchString(value) {
-		parts := strings.Split(value, ".")
-		if len(parts) > 0 {
-			v := fmt.Sprintf("local.%s.locals", parts[0])
-			for i, part := range parts {
-				if i > 0 && part != " " {
-					v = fmt.Sprintf("%s.%s", v, part)
-				}
+			if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+				ls.values["local."+attr.Name] = struct{}{}
 			}
-			ls.values[v] = struct{}{}
-			return nil
+		case "values":
+			ls.values["local.values"] = struct{}{}
 		}
 	}
-
-	return nil
 }
 
 // Search retrieves the processed local variables, categorizing them as special or generic, and returns them as a map.
@@ -97,11 +88,20 @@ func (ls LocalsSearch) Search() (map[string]string, error) {
 			return nil, fmt.Errorf("invalid local variable: %s", local)
 		}
 		name := parts[1]
-		if special, ok := SpecialLocals[name]; ok {
-			locals[name] = special(name)
-			continue
+		resolve := ls.resolvers.Default
+		if special, ok := ls.resolvers.Special[name]; ok {
+			resolve = special
+		}
+		if resolve == nil {
+			return nil, ErrResolveLocal(name)
+		}
+		ctx := ls.context
+		ctx.Name = name
+		value, err := resolve(ctx)
+		if err != nil {
+			return nil, utils.WrapError(ErrResolveLocal(name), err)
 		}
-		locals[name] = fmt.Sprintf(`read_terragrunt_config(find_in_parent_folders("%s.hcl"))`, name)
+		locals[name] = value
 	}
 	return locals, nil
 }
@@ -155,19 +155,3 @@ func localExists(localVars []LocalVariable, name string) bool {
 	}
 	return false
 }
-
-// extractLocalPath parses a part of a string to extract the local variable path.
-func extractLocalPath(part string) (string, error) {
-	subParts := strings.Split(part, ".")
-	localPath := "local"
-	for _, subPart := range subParts[1:] {
-		if trimmed := strings.Trim(strings.Split(subPart, "}")[0], " "); trimmed != "" {
-			localPath = fmt.Sprintf("%s.%s", localPath, trimmed)
-			break
-		}
-	}
-	if localPath == "local" {
-		return "", fmt.Errorf("invalid local variable path in: %s", part)
-	}
-	return localPath, nil
-}
@@ -0,0 +1,299 @@
+package terragrunt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Render builds the terragrunt.hcl document for the given Config with the built-in template
+// and returns it formatted the same way `terraform fmt` would.
+// Sections are always emitted in the same order, and unordered collections such as
+// inputs are sorted by key so that regenerating an unchanged Config yields identical bytes.
+func Render(c Config) ([]byte, error) {
+	tmpl, err := NewTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate(tmpl, c)
+}
+
+// renderSections renders every section of the terragrunt.hcl document of the given Config.
+// Each section is built as an hclwrite AST and ends with a newline.
+func renderSections(c Config) (Sections, error) {
+	var sections Sections
+	var err error
+
+	if sections.Dependencies, err = renderSection(func(body *hclwrite.Body) error {
+		return renderDependencies(body, c.Dependencies)
+	}); err != nil {
+		return sections, err
+	}
+	if sections.Locals, err = renderSection(func(body *hclwrite.Body) error {
+		return renderLocals(body, c.LocalVariables)
+	}); err != nil {
+		return sections, err
+	}
+	sections.Terraform, _ = renderSection(func(body *hclwrite.Body) error {
+		renderTerraform(body, c.OpenTofu)
+		return nil
+	})
+	sections.Include, _ = renderSection(func(body *hclwrite.Body) error {
+		renderInclude(body)
+		return nil
+	})
+	sections.Inputs, _ = renderSection(func(body *hclwrite.Body) error {
+		renderInputs(body, c.Inputs)
+		return nil
+	})
+	return sections, nil
+}
+
+// renderSection renders the body built by fn as a standalone HCL snippet.
+func renderSection(fn func(body *hclwrite.Body) error) (string, error) {
+	f := hclwrite.NewEmptyFile()
+	if err := fn(f.Body()); err != nil {
+		return "", err
+	}
+	return string(f.Bytes()), nil
+}
+
+// renderDependencies appends one dependency block per Terragrunt dependency.
+func renderDependencies(body *hclwrite.Body, dependencies []Dependency) error {
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	appendComment(body, "Dependencies")
+	for _, dep := range dependencies {
+		if dep.Name == "" {
+			return ErrRenderDependency(dep.ConfigPath)
+		}
+		block := body.AppendNewBlock("dependency", []string{dep.Name}).Body()
+		block.SetAttributeRaw("config_path", templateTokens(dep.ConfigPath))
+		block.SetAttributeValue("skip_outputs", cty.BoolVal(dep.SkipOutputs))
+	}
+	body.AppendNewline()
+	return nil
+}
+
+// renderLocals appends the locals block. Local variables whose name starts with '#'
+// are markers (e.g. the grunted locals delimiters) and are written as comments.
+func renderLocals(body *hclwrite.Body, locals []LocalVariable) error {
+	appendComment(body, "Locals")
+	block := body.AppendNewBlock("locals", nil).Body()
+	for _, local := range locals {
+		if strings.HasPrefix(local.Name, "#") {
+			appendComment(block, fmt.Sprintf("%s = %s", strings.TrimSpace(strings.TrimPrefix(local.Name, "#")), local.Value))
+			continue
+		}
+		if !hclsyntax.ValidIdentifier(local.Name) {
+			return ErrRenderLocal(local.Name)
+		}
+		block.SetAttributeRaw(local.Name, expressionTokens(local.Value))
+	}
+	return nil
+}
+
+// renderTerraform appends the terraform block holding the before hooks and the module source.
+func renderTerraform(body *hclwrite.Body, openTofu OpenTofu) {
+	appendComment(body, "OpenTofu Configuration")
+	block := body.AppendNewBlock("terraform", nil).Body()
+	for _, bh := range openTofu.BeforeHooks {
+		hook := block.AppendNewBlock("before_hook", []string{bh.Name}).Body()
+		hook.SetAttributeRaw("commands", stringListTokens(bh.Commands))
+		hook.SetAttributeRaw("execute", stringListTokens(bh.Execute))
+		block.AppendNewline()
+	}
+	block.SetAttributeRaw("source", templateTokens(openTofu.Source))
+}
+
+// renderInclude appends the include block pointing to the root terragrunt.hcl file.
+func renderInclude(body *hclwrite.Body) {
+	appendComment(body, "Include all settings from the root terragrunt.hcl file")
+	block := body.AppendNewBlock("include", nil).Body()
+	block.SetAttributeRaw("path", hclwrite.TokensForFunctionCall("find_in_parent_folders"))
+}
+
+// renderInputs appends the inputs object, sorted by key.
+func renderInputs(body *hclwrite.Body, inputs map[string]interface{}) {
+	appendComment(body, "Inputs to pass to the Terraform module")
+	body.SetAttributeRaw("inputs", objectTokens(inputs))
+}
+
+// valueTokens returns the tokens of a value decoded from YAML or JSON. Strings are expressions
+// when they parse as one (see expressionTokens), Literals are quoted strings and Expressions are
+// written as is. Lists are rendered as tuples and maps as objects sorted by key. Numbers, booleans
+// and null are rendered as HCL literals.
+func valueTokens(value interface{}) hclwrite.Tokens {
+	switch v := value.(type) {
+	case nil:
+		return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+	case string:
+		return expressionTokens(v)
+	case Literal:
+		return templateTokens(string(v))
+	case Expression:
+		return expressionTokens(string(v))
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(v))
+	case int:
+		return hclwrite.TokensForValue(cty.NumberIntVal(int64(v)))
+	case int64:
+		return hclwrite.TokensForValue(cty.NumberIntVal(v))
+	case uint64:
+		return hclwrite.TokensForValue(cty.NumberUIntVal(v))
+	case float64:
+		return hclwrite.TokensForValue(cty.NumberFloatVal(v))
+	case []interface{}:
+		elems := make([]hclwrite.Tokens, 0, len(v))
+		for _, elem := range v {
+			elems = append(elems, valueTokens(elem))
+		}
+		return hclwrite.TokensForTuple(elems)
+	case map[string]interface{}:
+		return objectTokens(v)
+	default:
+		return templateTokens(fmt.Sprint(v))
+	}
+}
+
+// objectTokens returns the tokens of an object holding the given attributes, sorted by key.
+// Keys that are not valid identifiers are quoted.
+func objectTokens(values map[string]interface{}) hclwrite.Tokens {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]hclwrite.ObjectAttrTokens, 0, len(keys))
+	for _, key := range keys {
+		name := hclwrite.TokensForIdentifier(key)
+		if !hclsyntax.ValidIdentifier(key) {
+			name = hclwrite.TokensForValue(cty.StringVal(key))
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  name,
+			Value: valueTokens(values[key]),
+		})
+	}
+	return hclwrite.TokensForObject(attrs)
+}
+
+// appendComment appends a single line comment to the given body.
+func appendComment(body *hclwrite.Body, comment string) {
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte(fmt.Sprintf("# %s\n", comment))},
+	})
+}
+
+// expressionTokens returns the tokens of value when it is a valid HCL expression
+// (a reference, a function call, a literal...). Anything else is rendered as a
+// quoted template string so that the output is always valid HCL.
+func expressionTokens(value string) hclwrite.Tokens {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return templateTokens(value)
+	}
+
+	_, diags := hclsyntax.ParseExpression([]byte(value), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return templateTokens(value)
+	}
+
+	f, diags := hclwrite.ParseConfig([]byte(fmt.Sprintf("v = %s\n", value)), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return templateTokens(value)
+	}
+	attr := f.Body().GetAttribute("v")
+	if attr == nil {
+		return templateTokens(value)
+	}
+	return attr.Expr().BuildTokens(nil)
+}
+
+// stringListTokens returns the tokens of a tuple of quoted template strings.
+func stringListTokens(values []string) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, 0, len(values))
+	for _, value := range values {
+		elems = append(elems, templateTokens(value))
+	}
+	return hclwrite.TokensForTuple(elems)
+}
+
+// templateTokens returns value as a quoted HCL template string. Quotes, backslashes
+// and control characters are escaped while `${...}` and `%{...}` sequences are kept
+// as is, so that interpolations such as "${local.email}" keep working.
+func templateTokens(value string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte(escapeTemplateString(value))},
+		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	}
+}
+
+// escapeTemplateString escapes the characters that cannot appear verbatim in a quoted HCL string.
+// Interpolation and directive sequences are copied untouched, as their content is an expression.
+func escapeTemplateString(value string) string {
+	var sb strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if (r == '$' || r == '%') && i+2 < len(runes) && runes[i+1] == r && runes[i+2] == '{' {
+			// Already escaped literal sequence ($${ or %%{).
+			sb.WriteString(string(runes[i : i+3]))
+			i += 2
+			continue
+		}
+		if (r == '$' || r == '%') && i+1 < len(runes) && runes[i+1] == '{' {
+			end := templateSequenceEnd(runes, i+1)
+			sb.WriteString(string(runes[i : end+1]))
+			i = end
+			continue
+		}
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// templateSequenceEnd returns the index of the brace closing the one found at start,
+// or the last index of runes when the sequence is not terminated.
+func templateSequenceEnd(runes []rune, start int) int {
+	depth := 0
+	inString := false
+	for i := start; i < len(runes); i++ {
+		switch {
+		case inString && runes[i] == '\\':
+			i++
+		case runes[i] == '"':
+			inString = !inString
+		case !inString && runes[i] == '{':
+			depth++
+		case !inString && runes[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(runes) - 1
+}
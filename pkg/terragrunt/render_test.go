@@ -0,0 +1,69 @@
+package terragrunt
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestEscapeTemplateString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "hello", want: "hello"},
+		{name: "quote", value: `say "hi"`, want: `say \"hi\"`},
+		{name: "backslash", value: `C:\tmp`, want: `C:\\tmp`},
+		{name: "control characters", value: "a\nb\tc\r", want: `a\nb\tc\r`},
+		{name: "interpolation kept", value: "${local.email}", want: "${local.email}"},
+		{name: "interpolation with quotes kept", value: `${get_env("A", "b")}-x`, want: `${get_env("A", "b")}-x`},
+		{name: "nested braces kept", value: `${merge({a = 1}, local.b)}`, want: `${merge({a = 1}, local.b)}`},
+		{name: "directive kept", value: `%{ if true }x%{ endif }`, want: `%{ if true }x%{ endif }`},
+		{name: "escaped interpolation kept", value: "$${literal}", want: "$${literal}"},
+		{name: "escaped directive kept", value: "%%{literal}", want: "%%{literal}"},
+		{name: "unterminated interpolation", value: "${local.a", want: "${local.a"},
+		{name: "go template braces", value: `{{ .Author }} "x"`, want: `{{ .Author }} \"x\"`},
+		{name: "dollar without brace", value: "$HOME 100%", want: "$HOME 100%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeTemplateString(tt.value); got != tt.want {
+				t.Errorf("escapeTemplateString(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "null", value: nil, want: "null"},
+		{name: "boolean", value: true, want: "true"},
+		{name: "integer", value: 3, want: "3"},
+		{name: "number", value: 1.5, want: "1.5"},
+		{name: "reference string", value: "local.env", want: "local.env"},
+		{name: "function call string", value: `get_env("A")`, want: `get_env("A")`},
+		{name: "free text string", value: "hello world", want: `"hello world"`},
+		{name: "empty string", value: "", want: `""`},
+		{name: "literal", value: Literal("local.env"), want: `"local.env"`},
+		{name: "literal with quotes", value: Literal(`say "hi"`), want: `"say \"hi\""`},
+		{name: "literal with go template", value: Literal("{{ $labels.instance }}"), want: `"{{ $labels.instance }}"`},
+		{name: "expression", value: Expression("merge(local.a, local.b)"), want: "merge(local.a, local.b)"},
+		{name: "list", value: []interface{}{Literal("a"), 1}, want: `["a", 1]`},
+		{name: "map sorted and quoted keys", value: map[string]interface{}{"b": 1, "a b": Literal("x")}, want: "{\n  \"a b\" = \"x\"\n  b     = 1\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(hclwrite.Format(valueTokens(tt.value).Bytes()))
+			if got != tt.want {
+				t.Errorf("valueTokens(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
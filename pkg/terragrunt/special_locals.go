@@ -1,30 +1,88 @@
 package terragrunt
 
-import "fmt"
-
-// LocalFunction defines a type for functions that accept a string argument and return a string.
-type LocalFunction func(string) string
-
-var (
-	// SpecialLocals is a map defining special functions used to create default locals during the LocalsSearch.Search() function
-	// Each function is tailored to return specific information as a string, based on a provided argument.
-	// - "values": Generates a call to read a Terragrunt configuration file, expecting the filename (without extension) as input.
-	// - "email": Returns the value of the TF_VAR_EMAIL environment variable, useful for templates needing access to an email.
-	// - "template_root": Provides the root directory for Terraform templates from the TF_VAR_TEMPLATE_ROOT environment variable.
-	SpecialLocals = map[string]LocalFunction{
-		"values": func(s string) string {
-			// Generates a Terragrunt configuration file read command, formatted with the provided string.
-			return fmt.Sprintf(`read_terragrunt_config("%s.hcl")`, s)
-		},
-		"email": func(s string) string {
-			// Returns the command to get the email environment variable.
-			// The argument `s` is not used here, but the function signature is kept consistent for map compatibility.
-			return `get_env("TF_VAR_EMAIL", "")`
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// LocalContext describes a local variable being resolved and the block it is generated for.
+type LocalContext struct {
+	Name     string            // Name of the local variable.
+	Metadata map[string]string // Metadata of the block.
+	Dir      string            // Directory the block is generated from.
+	RepoRoot string            // Root of the repository.
+}
+
+// LocalFunction resolves a local variable to the HCL expression it is defined with.
+type LocalFunction func(ctx LocalContext) (string, error)
+
+// Environment variables read by the built-in special locals.
+const (
+	// DefaultEmailEnvVar is the environment variable read by the "email" special local.
+	DefaultEmailEnvVar = "TF_VAR_EMAIL"
+	// DefaultTemplateRootEnvVar is the environment variable read by the "template_root" special local.
+	DefaultTemplateRootEnvVar = "TF_VAR_TEMPLATE_ROOT"
+)
+
+// LocalResolvers resolve the local variables generated when referenced (see LocalsSearch.Search):
+// Special holds the resolvers of some locals by name, and Default resolves the others.
+type LocalResolvers struct {
+	Special map[string]LocalFunction
+	Default LocalFunction
+}
+
+// NewLocalResolvers returns the built-in resolvers, reading the given environment variables:
+// - "values": Generates a call to read the Terragrunt configuration file named after the local.
+// - "email": Returns the value of the emailEnvVar environment variable, useful for templates needing access to an email.
+// - "template_root": Provides the root directory for Terraform templates from the templateRootEnvVar environment variable.
+// The other locals read the Terragrunt configuration file named after them in a parent folder.
+// More resolvers can be added with Register.
+func NewLocalResolvers(emailEnvVar, templateRootEnvVar string) LocalResolvers {
+	return LocalResolvers{
+		Special: map[string]LocalFunction{
+			"values": func(ctx LocalContext) (string, error) {
+				// Generates a Terragrunt configuration file read command, formatted with the name of the local.
+				return fmt.Sprintf(`read_terragrunt_config("%s.hcl")`, ctx.Name), nil
+			},
+			"email": func(ctx LocalContext) (string, error) {
+				// Returns the command to get the email environment variable.
+				return fmt.Sprintf(`get_env("%s", "")`, emailEnvVar), nil
+			},
+			"template_root": func(ctx LocalContext) (string, error) {
+				// Returns the command to get the template root environment variable.
+				return fmt.Sprintf(`get_env("%s", "")`, templateRootEnvVar), nil
+			},
 		},
-		"template_root": func(s string) string {
-			// Returns the command to get the template root environment variable.
-			// The argument `s` is not used here, but the function signature is kept consistent for map compatibility.
-			return `get_env("TF_VAR_TEMPLATE_ROOT", "")`
+		Default: func(ctx LocalContext) (string, error) {
+			return fmt.Sprintf(`read_terragrunt_config(find_in_parent_folders("%s.hcl"))`, ctx.Name), nil
 		},
 	}
-)
+}
+
+// DefaultLocalResolvers returns the built-in resolvers, reading the default environment variables.
+func DefaultLocalResolvers() LocalResolvers {
+	return NewLocalResolvers(DefaultEmailEnvVar, DefaultTemplateRootEnvVar)
+}
+
+// Register registers the resolver of the local variable name, replacing the existing one if any.
+func (r LocalResolvers) Register(name string, fn LocalFunction) {
+	r.Special[name] = fn
+}
+
+// TemplateLocal returns a resolver executing the given Go template with the LocalContext of the local,
+// for instance `read_terragrunt_config("${get_repo_root()}/accounts/{{ .Metadata.account }}.hcl")`.
+// Referencing a metadata key the block does not define is an error.
+func TemplateLocal(text string) (LocalFunction, error) {
+	tmpl, err := template.New("local").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx LocalContext) (string, error) {
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, ctx); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}, nil
+}
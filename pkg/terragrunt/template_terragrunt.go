@@ -1,41 +1,95 @@
 package terragrunt
 
-// DefaultTerragruntTemplate is the default Terragrunt configuration template used by Grunter.
-const DefaultTerragruntTemplate = `{{ if .Dependencies }}# Dependencies{{end}}{{- range .Dependencies }}
-dependency "{{.Name}}" {
-  config_path  = "{{.ConfigPath}}"
-  skip_outputs = {{.SkipOutputs}}
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Partials lists the named sections of the terragrunt.hcl template, in document order.
+// A user template can redefine any of them with {{ define "name" }}...{{ end }} and keep
+// the built-in definition of the others.
+var Partials = []string{"header", "dependencies", "locals", "terraform", "include", "inputs"}
+
+// DefaultTemplate lays out the sections of a terragrunt.hcl document.
+// The blank lines between sections are part of the layout, not of the sections.
+const DefaultTemplate = `{{ template "header" . }}{{ template "dependencies" . }}{{ template "locals" . }}
+{{ template "terraform" . }}
+{{ template "include" . }}
+{{ template "inputs" . }}`
+
+// defaultPartials holds the built-in definition of every partial. The header is empty.
+const defaultPartials = `{{ define "header" }}{{ end }}` +
+	`{{ define "dependencies" }}{{ .Sections.Dependencies }}{{ end }}` +
+	`{{ define "locals" }}{{ .Sections.Locals }}{{ end }}` +
+	`{{ define "terraform" }}{{ .Sections.Terraform }}{{ end }}` +
+	`{{ define "include" }}{{ .Sections.Include }}{{ end }}` +
+	`{{ define "inputs" }}{{ .Sections.Inputs }}{{ end }}`
+
+// TemplateData is the data a terragrunt.hcl template is executed with.
+type TemplateData struct {
+	Config   Config   // Configuration being rendered.
+	Sections Sections // Built-in rendering of every section of the configuration.
 }
-{{- end }}{{ if .Dependencies }}
 
-{{end}}# Locals
-locals {
-  {{- range .LocalVariables }}
-  {{.Name}} = {{.Value}}
-  {{- end }}
+// Sections holds the built-in rendering of every section of a terragrunt.hcl document.
+// Empty sections, such as the dependencies of a configuration without any, are empty strings.
+type Sections struct {
+	Dependencies string // dependency blocks.
+	Locals       string // locals block.
+	Terraform    string // terraform block, with the before hooks and the module source.
+	Include      string // include block of the root terragrunt.hcl file.
+	Inputs       string // inputs attribute.
 }
 
-# OpenTofu Configuration
-terraform {
-  {{- range .OpenTofu.BeforeHooks }}
-  before_hook "{{.Name}}" {
-    commands = [{{range .Commands}}"{{.}}",{{end}}]
-    execute  = [{{range .Execute}}"{{.}}",{{end}}]
-  }
-  {{- end }}
+// Templates holds the paths of the template files a configuration is rendered with.
+// Empty paths fall back to the built-in templates.
+type Templates struct {
+	Terragrunt string `json:"terragrunt"` // terragrunt.hcl template, possibly only redefining some partials.
+	Values     string `json:"values"`     // values.hcl template.
+}
 
-  source = "{{.OpenTofu.Source}}"
+// templateFuncs are the functions available to terragrunt.hcl templates, so that partials
+// render values the same way the built-in sections do.
+var templateFuncs = template.FuncMap{
+	// expr renders a value as an HCL expression, or as a quoted string if it is not one.
+	// Lists and maps, such as typed inputs, are rendered as tuples and objects.
+	"expr": func(value interface{}) string { return string(valueTokens(value).Bytes()) },
+	// quote renders a value as a quoted HCL string, keeping its interpolations.
+	"quote": func(value string) string { return string(templateTokens(value).Bytes()) },
 }
 
-# Include all settings from the root terragrunt.hcl file
-include {
-  path = find_in_parent_folders()
+// NewTemplate returns the built-in terragrunt.hcl template with the given template texts parsed
+// over it, in order. A text made of definitions only overrides the matching partials and keeps
+// the built-in layout; any other content replaces the layout.
+func NewTemplate(texts ...string) (*template.Template, error) {
+	tmpl := template.New("terragrunt").Funcs(templateFuncs).Option("missingkey=error")
+	for _, text := range append([]string{defaultPartials, DefaultTemplate}, texts...) {
+		if _, err := tmpl.Parse(text); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
 }
 
-# Inputs to pass to the Terraform module
-inputs = {
-  {{- range $key, $value := .Inputs }}
-  {{$key}} = {{$value}}
-  {{- end }}
+// RenderTemplate executes tmpl for the given Config and returns the result formatted the same way
+// `terraform fmt` would. The result must be valid HCL.
+func RenderTemplate(tmpl *template.Template, c Config) ([]byte, error) {
+	sections, err := renderSections(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, TemplateData{Config: c, Sections: sections}); err != nil {
+		return nil, err
+	}
+
+	if _, diags := hclsyntax.ParseConfig(out.Bytes(), "terragrunt.hcl", hcl.InitialPos); diags.HasErrors() {
+		return nil, ErrInvalidTemplateOutput(diags)
+	}
+	return hclwrite.Format(out.Bytes()), nil
 }
-`
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Predefined errors for decoding operations.
+var (
+	// ErrUnknownField is returned by strict decoding when a key does not match any field.
+	ErrUnknownField = func(key, suggestion string) error {
+		if suggestion == "" {
+			return fmt.Errorf("unknown field '%s'", key)
+		}
+		return fmt.Errorf("unknown field '%s', did you mean '%s'?", key, suggestion)
+	}
+)
+
+// maxSuggestionDistance is the maximum edit distance for a field name to be suggested.
+const maxSuggestionDistance = 3
+
+// Decode decodes input, as produced by a YAML or JSON unmarshaller, into output.
+// In strict mode, every key of input that does not match a field of output is
+// reported, with the path of the key and the nearest valid field name.
+func Decode(input, output interface{}, strict bool) error {
+	if strict {
+		if err := UnknownFields(input, reflect.TypeOf(output)); err != nil {
+			return err
+		}
+	}
+	return mapstructure.Decode(input, output)
+}
+
+// UnknownFields walks input along the type t and reports every key that does not
+// match a field of the corresponding struct. Field names are matched case-insensitively
+// against their json or yaml tag, like the decoder does. The keys of a map have no order:
+// they are walked sorted, and callers knowing their positions sort the errors by position.
+func UnknownFields(input interface{}, t reflect.Type) error {
+	var errs Errors
+	unknownFields(input, t, "", &errs)
+	return errs.ErrorOrNil()
+}
+
+// unknownFields is the recursive helper of UnknownFields.
+func unknownFields(input interface{}, t reflect.Type, path string, errs *Errors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		values, ok := input.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := structFields(t)
+		for _, key := range sortedKeys(values) {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*errs = errs.Append(AtPath(JoinPath(path, key), ErrUnknownField(key, nearestField(key, fields))))
+				continue
+			}
+			unknownFields(values[key], field.Type, JoinPath(path, key), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		values, ok := input.([]interface{})
+		if !ok {
+			return
+		}
+		for i, value := range values {
+			unknownFields(value, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		values, ok := input.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(values) {
+			unknownFields(values[key], t.Elem(), JoinPath(path, key), errs)
+		}
+	}
+}
+
+// sortedKeys returns the keys of values in a stable order.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// structFields indexes the exported fields of t by their lowercased key name.
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fields[strings.ToLower(fieldKey(field))] = field
+	}
+	return fields
+}
+
+// fieldKey returns the key of a struct field as written in configuration files.
+func fieldKey(field reflect.StructField) string {
+	for _, tag := range []string{"json", "yaml", "mapstructure"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// nearestField returns the field key closest to key, or an empty string when none is close enough.
+func nearestField(key string, fields map[string]reflect.StructField) string {
+	suggestion, best := "", maxSuggestionDistance+1
+	for name, field := range fields {
+		distance := levenshtein.Distance(strings.ToLower(key), name, nil)
+		if distance < best || (distance == best && fieldKey(field) < suggestion) {
+			suggestion, best = fieldKey(field), distance
+		}
+	}
+	return suggestion
+}
+
+// LookupKey returns the key of m matching key case-insensitively, like the decoder does, and its value.
+func LookupKey(m map[string]interface{}, key string) (string, interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return key, v, true
+	}
+	for _, k := range sortedKeys(m) {
+		if strings.EqualFold(k, key) {
+			return k, m[k], true
+		}
+	}
+	return "", nil, false
+}
+
+// CopyMap returns a shallow copy of m.
+func CopyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
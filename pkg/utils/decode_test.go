@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	type hook struct {
+		Name    string   `json:"name"`
+		Execute []string `json:"execute"`
+	}
+	type spec struct {
+		Name        string            `json:"name"`
+		Template    string            `json:"template"`
+		BeforeHooks []hook            `json:"beforeHooks"`
+		Inputs      map[string]string `json:"inputs"`
+		Dir         string            `json:"-"`
+	}
+	tests := []struct {
+		name   string
+		input  map[string]interface{}
+		strict bool
+		want   []string
+	}{
+		{name: "known fields", input: map[string]interface{}{"name": "a", "template": "x"}, strict: true},
+		{name: "keys match case-insensitively", input: map[string]interface{}{"Name": "a", "beforehooks": []interface{}{}}, strict: true},
+		{name: "lenient", input: map[string]interface{}{"nmae": "a"}},
+		{name: "suggestion", input: map[string]interface{}{"nmae": "a"}, strict: true, want: []string{"nmae: unknown field 'nmae', did you mean 'name'?"}},
+		{name: "suggestion keeps the case of the field", input: map[string]interface{}{"beforeHook": []interface{}{}}, strict: true, want: []string{"beforeHook: unknown field 'beforeHook', did you mean 'beforeHooks'?"}},
+		{name: "no close field", input: map[string]interface{}{"replicas": 3}, strict: true, want: []string{"replicas: unknown field 'replicas'"}},
+		{name: "ignored field", input: map[string]interface{}{"dir": "x"}, strict: true, want: []string{"dir: unknown field 'dir'"}},
+		{
+			name:   "nested in a list",
+			input:  map[string]interface{}{"beforeHooks": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"exec": []interface{}{}}}},
+			strict: true,
+			want:   []string{"beforeHooks[1].exec: unknown field 'exec', did you mean 'execute'?"},
+		},
+		{name: "map keys are free", input: map[string]interface{}{"inputs": map[string]interface{}{"anything": "x"}}, strict: true},
+		{
+			name:   "every problem reported",
+			input:  map[string]interface{}{"nmae": "a", "templat": "x"},
+			strict: true,
+			want:   []string{"nmae: unknown field 'nmae', did you mean 'name'?", "templat: unknown field 'templat', did you mean 'template'?"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out spec
+			err := Decode(tt.input, &out, tt.strict)
+			var got []string
+			for _, e := range ErrorList(err) {
+				fieldErr, ok := e.(*FieldError)
+				if !ok {
+					t.Fatalf("Decode() error %v is not located", e)
+				}
+				got = append(got, fieldErr.Path+": "+fieldErr.Err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position in a configuration file. Line and Column are 1-based and
+// zero when only the file is known.
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String formats the position as file:line:col.
+func (p Pos) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// FieldError locates an error in a configuration document. Path is the path of the
+// offending field in the document (e.g. "spec.blocks[2].inputs.name") and Pos its
+// position in the source file once it has been resolved.
+type FieldError struct {
+	Path string
+	Pos  Pos
+	Err  error
+}
+
+// Error prefixes the message with the position of the field when it is known.
+func (e *FieldError) Error() string {
+	if e.Pos.File == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Err)
+}
+
+// Unwrap returns the located error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// AtPath prefixes the path of err, and of every error it holds, with the given
+// field path segment (e.g. "inputs.name" or "blocks[2]"). Nil errors are returned as is.
+func AtPath(segment string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errs, ok := err.(Errors); ok {
+		located := make(Errors, 0, len(errs))
+		for _, e := range errs {
+			located = append(located, AtPath(segment, e))
+		}
+		return located
+	}
+
+	if fieldErr, ok := err.(*FieldError); ok {
+		return &FieldError{Path: JoinPath(segment, fieldErr.Path), Pos: fieldErr.Pos, Err: fieldErr.Err}
+	}
+	return &FieldError{Path: segment, Err: err}
+}
+
+// JoinPath joins two field path segments, omitting the dot before index segments.
+func JoinPath(parent, child string) string {
+	switch {
+	case parent == "":
+		return child
+	case child == "":
+		return parent
+	case strings.HasPrefix(child, "["):
+		return parent + child
+	default:
+		return parent + "." + child
+	}
+}
+
+// ParentPath returns the path of the field holding the given path, and false for the root.
+func ParentPath(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	i := strings.LastIndexAny(path, ".[")
+	if i < 0 {
+		return "", true
+	}
+	return path[:i], true
+}
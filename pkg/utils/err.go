@@ -1,11 +1,85 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
+// WrapError wraps originalErr with customErr. When originalErr is located in a
+// configuration document, the location is kept on the outermost error.
 func WrapError(customErr, originalErr error) error {
+	if fieldErr, ok := originalErr.(*FieldError); ok {
+		return &FieldError{Path: fieldErr.Path, Pos: fieldErr.Pos, Err: WrapError(customErr, fieldErr.Err)}
+	}
 	return fmt.Errorf("%w: %v", customErr, originalErr)
 }
 
+// Errors collects several errors so that all of them can be reported at once
+// instead of stopping at the first one.
+type Errors []error
+
+// Error joins the messages of all collected errors, one per line.
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap exposes the collected errors to errors.Is and errors.As.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Append adds err to the collection, flattening nested Errors. Nil errors are ignored.
+func (e Errors) Append(err error) Errors {
+	if err == nil {
+		return e
+	}
+	if errs, ok := err.(Errors); ok {
+		return append(e, errs...)
+	}
+	return append(e, err)
+}
+
+// ErrorOrNil returns nil when nothing was collected, the single error when only one
+// was collected, and the collection itself otherwise.
+func (e Errors) ErrorOrNil() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	default:
+		return e
+	}
+}
+
+// WrapErrors wraps every error held by originalErr with customErr, keeping them separate.
+func WrapErrors(customErr, originalErr error) error {
+	errs, ok := originalErr.(Errors)
+	if !ok {
+		return WrapError(customErr, originalErr)
+	}
+	wrapped := make(Errors, 0, len(errs))
+	for _, err := range errs {
+		wrapped = append(wrapped, WrapError(customErr, err))
+	}
+	return wrapped
+}
+
+// ErrorList returns the individual errors held by err.
+func ErrorList(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if errs, ok := err.(Errors); ok {
+		return errs
+	}
+	return []error{err}
+}
+
 // Predefined errors for file operations.
 var (
 	// ErrFileNotFound indicates the .hcl file was not found in the expected locations.
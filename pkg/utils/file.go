@@ -13,84 +13,26 @@ var (
 	ErrFindUpwards = fmt.Errorf("target directory not found")
 )
 
-// FindUpwards searches for the first part of the path starting from the current directory and moving upwards.
-// Returns the path to the found directory or an empty string if not found.
+// FindUpwards searches for a file or directory named target under startDir, then under each
+// of its parents in turn, up to maxDepth levels. The search runs against the repository index
+// (see RepositoryIndex), so ignored directories are never looked into and results are cached.
+// Returns the path to the found file or directory.
 func FindUpwards(startDir, target string, maxDepth int) (string, error) {
 	if maxDepth <= 0 {
 		return "", fmt.Errorf("folder search max depth reached")
 	}
 
-	var foundPath string
-	err := filepath.WalkDir(startDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return fmt.Errorf("walkDir: %w", err)
-		}
-		if d.IsDir() && d.Name() == target {
-			foundPath = path
-			return nil
-		}
-		return nil
-	})
+	ix, err := RepositoryIndex(startDir)
 	if err != nil {
 		return "", WrapError(ErrFindUpwards, err)
 	}
-
-	if foundPath != "" {
-		return foundPath, nil
-	}
-
-	newStartDir := filepath.Dir(startDir)
-	if newStartDir == "/" {
-		return "", WrapError(ErrFindUpwards, fmt.Errorf("target '%s' not found in '%s'", target, startDir))
-	}
-
-	// If the target was not found in the current directory, search downwards
-	foundPath, _ = findDownwards(newStartDir, target)
-	if foundPath != "" {
-		return foundPath, nil
-	}
-
-	return FindUpwards(newStartDir, target, maxDepth-1)
-}
-
-// findDownwards searches for the remaining parts of the path within the given directory.
-func findDownwards(startDir string, target string) (string, error) {
-	currentDir := startDir
-	parts := strings.Split(target, "/")
-	for _, part := range parts {
-		found, err := findInDirectory(currentDir, part)
-		if err != nil {
-			return "", err
-		}
-		currentDir = found
-	}
-	return currentDir, nil
-}
-
-// findInDirectory searches for a target directory or file within the given directory.
-func findInDirectory(dir, target string) (string, error) {
-	var targetPath string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if filepath.Base(path) == target {
-			targetPath = path
-			return nil
-		}
-		return nil
-	})
-	if err != nil {
-		return "", err
-	}
-
-	if targetPath != "" {
-		return targetPath, nil
-	}
-
-	return "", fmt.Errorf("findInDirectory: target '%s' not found in '%s'", target, dir)
+	return ix.FindUpwards(startDir, target, maxDepth)
 }
 
-// FindFileInParentTarget searches a file in a parent target folder.
-func FindFileInParentTarget(parentFolder, targetFolder, fileName string, maxDepth int) (string, error) {
-	currentDir, err := os.Getwd()
+// FindFileInParentTarget searches a file in a parent target folder, starting from dir.
+// The returned path is relative to dir.
+func FindFileInParentTarget(dir, parentFolder, targetFolder, fileName string, maxDepth int) (string, error) {
+	currentDir, err := filepath.Abs(dir)
 	if err != nil {
 		return "", err
 	}
@@ -101,37 +43,31 @@ func FindFileInParentTarget(parentFolder, targetFolder, fileName string, maxDept
 		return "", fmt.Errorf("invalid parentFolder path")
 	}
 
-	// Find the first part of the parentFolder path
-	foundPath, err := FindUpwards(currentDir, parts[0], maxDepth)
+	ix, err := RepositoryIndex(currentDir)
 	if err != nil {
 		return "", err
 	}
 
-	// Find the remaining parts of the parentFolder path
-	if len(parts) > 1 {
-		subPath := strings.Join(parts[1:], "/")
-		foundPath, err = findDownwards(foundPath, subPath)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	// Now find the targetFolder and fileName within the final foundPath
-	targetFolderPath, err := findInDirectory(foundPath, targetFolder)
+	// Find the first part of the parentFolder path
+	foundPath, err := ix.FindUpwards(currentDir, parts[0], maxDepth)
 	if err != nil {
 		return "", err
 	}
 
-	filePath, err := findInDirectory(targetFolderPath, fileName)
-	if err != nil {
-		return "", err
+	// Find the remaining parts of the parentFolder path, then the targetFolder and
+	// fileName within the final foundPath
+	subPath := strings.Join(append(parts[1:], targetFolder, fileName), "/")
+	filePath, ok := ix.FindIn(foundPath, subPath)
+	if !ok {
+		return "", fmt.Errorf("target '%s' not found in '%s'", subPath, foundPath)
 	}
 
 	return ComputeRelativePath(currentDir, filePath)
 }
 
-func FindFileInParent(fileName string, maxDepth int) (string, error) {
-	currentDir, err := os.Getwd()
+// FindFileInParent searches a file in dir or its parents. The returned path is relative to dir.
+func FindFileInParent(dir, fileName string, maxDepth int) (string, error) {
+	currentDir, err := filepath.Abs(dir)
 	if err != nil {
 		return "", err
 	}
@@ -159,6 +95,16 @@ func ComputeRelativePath(basePath, targetPath string) (string, error) {
 	return relPath, nil // Return the relative path
 }
 
+// RelativeTo returns the path of target relative to base when it can be computed (see
+// ComputeRelativePath), and target itself otherwise.
+func RelativeTo(base, target string) string {
+	rel, err := ComputeRelativePath(base, target)
+	if err != nil {
+		return target
+	}
+	return rel
+}
+
 func DoesFileOrDirExists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
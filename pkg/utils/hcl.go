@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -70,19 +71,19 @@ func Get(h HCL, key string) (string, bool) {
 	return "", false
 }
 
-// GetHCLFromParent retrieves the configuration from an HCL file located within the project directory or any parent directory.
+// GetHCLFromParent retrieves the configuration from an HCL file located within the dir directory or any parent directory.
 // The function searches for a file with the provided name appended with ".hcl".
 // It wraps and returns any error encountered during the file search or parsing process, with additional context.
-func GetHCLFromParent(name string) (HCL, error) {
-	// Attempt to locate the .hcl file in the current or any parent directory.
-	cloudFile, err := FindFileInParent(name+".hcl", 50)
+func GetHCLFromParent(dir, name string) (HCL, error) {
+	// Attempt to locate the .hcl file in the given or any parent directory.
+	cloudFile, err := FindFileInParent(dir, name+".hcl", 50)
 	if err != nil {
 		// Return an enhanced error message if the file is not found.
 		return HCL{}, WrapError(ErrFileNotFound, err)
 	}
 
 	// Parse the found .hcl file into the HCL struct.
-	h, err := ParseHCL(cloudFile)
+	h, err := ParseHCL(filepath.Join(dir, cloudFile))
 	if err != nil {
 		// Return an enhanced error message if parsing fails.
 		return HCL{}, WrapError(ErrParseFailed, err)
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/romainframe/grunter/pkg/env"
+)
+
+// Index is an in-memory index of the files and directories of a repository, keyed by
+// base name. It is built once with a single ignore-aware walk (see WalkRepository) and
+// answers upward and downward searches without touching the filesystem again. Search
+// results are cached, so that blocks sharing the same lookups only pay for them once.
+// Files created afterwards are added with AddToIndexes. An Index is safe for concurrent use.
+type Index struct {
+	root    string
+	mu      sync.RWMutex        // Guards entries.
+	entries map[string][]string // Absolute paths by base name, closest to the root first.
+	cache   sync.Map            // Results of FindUpwards, by query.
+}
+
+var (
+	indexesMu sync.Mutex
+	indexes   = map[string]*Index{}
+)
+
+// NewIndex walks the repository rooted at root and indexes every file and directory it holds.
+func NewIndex(root string) (*Index, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &Index{root: root, entries: map[string][]string{}}
+	err = WalkRepository(root, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root {
+			ix.entries[d.Name()] = append(ix.entries[d.Name()], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, paths := range ix.entries {
+		sortPaths(paths)
+	}
+	return ix, nil
+}
+
+// sortPaths orders the candidates of a name so that the shallowest match, then the first in
+// lexical order, wins.
+func sortPaths(paths []string) {
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], string(filepath.Separator)), strings.Count(paths[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return paths[i] < paths[j]
+	})
+}
+
+// AddToIndexes adds the file or directory at path, created after the repository indexes were
+// built, to the indexes holding it, along with its content and the parent directories they lack.
+// Paths ignored by the repository are left out, as when an index is built. Cached searches are
+// forgotten, as they may now find a closer match.
+func AddToIndexes(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	indexesMu.Lock()
+	var holding []*Index
+	for root, ix := range indexes {
+		if path != root && isWithin(root, path) {
+			holding = append(holding, ix)
+		}
+	}
+	indexesMu.Unlock()
+
+	for _, ix := range holding {
+		if err := ix.add(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add indexes path, a descendant of the root of the index, and its missing parents.
+func (ix *Index) add(path string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// Walk from the highest parent missing from the index, so that it is indexed too.
+	top := path
+	for parent := filepath.Dir(top); parent != ix.root && !ix.has(parent); parent = filepath.Dir(parent) {
+		top = parent
+	}
+	err := WalkRepository(ix.root, top, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !ix.has(path) {
+			ix.entries[d.Name()] = append(ix.entries[d.Name()], path)
+			sortPaths(ix.entries[d.Name()])
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ix.cache.Range(func(key, _ interface{}) bool {
+		ix.cache.Delete(key)
+		return true
+	})
+	return nil
+}
+
+// has reports whether path is indexed. The caller holds ix.mu.
+func (ix *Index) has(path string) bool {
+	for _, p := range ix.entries[filepath.Base(path)] {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// RepositoryIndex returns the index of the repository holding dir, building it on first use.
+// The repository root is env.GRUNT_REPO_ROOT when dir is inside it, the closest parent of dir
+// holding a .git directory otherwise, then the working directory when it holds dir, and dir
+// itself as a last resort.
+func RepositoryIndex(dir string) (*Index, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := indexRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	if ix, ok := indexes[root]; ok {
+		return ix, nil
+	}
+	ix, err := NewIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	indexes[root] = ix
+	return ix, nil
+}
+
+// indexRoot returns the root of the repository holding dir, an absolute path.
+func indexRoot(dir string) (string, error) {
+	if env.GRUNT_REPO_ROOT != "" {
+		root, err := filepath.Abs(env.GRUNT_REPO_ROOT)
+		if err != nil {
+			return "", err
+		}
+		if isWithin(root, dir) {
+			return root, nil
+		}
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		if DoesFileOrDirExists(filepath.Join(current, ".git")) {
+			return current, nil
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	if cwd, err := os.Getwd(); err == nil && isWithin(cwd, dir) {
+		return cwd, nil
+	}
+	return dir, nil
+}
+
+// Root returns the directory the index was built from.
+func (ix *Index) Root() string {
+	return ix.root
+}
+
+// FindIn returns the first file or directory named name found under dir, the shallowest first.
+// The name may hold several slash-separated parts, which are then searched for one within another.
+func (ix *Index) FindIn(dir, name string) (string, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	current := dir
+	for _, part := range strings.Split(name, "/") {
+		found := false
+		for _, path := range ix.entries[part] {
+			if isWithin(current, path) && path != current {
+				current, found = path, true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return current, true
+}
+
+// FindUpwards searches for a file or directory named target under startDir, then under each of
+// its parents in turn, up to maxDepth levels and never above the root of the index.
+// Returns the absolute path of the closest match.
+func (ix *Index) FindUpwards(startDir, target string, maxDepth int) (string, error) {
+	startDir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%d", startDir, target, maxDepth)
+	if found, ok := ix.cache.Load(key); ok {
+		return found.(string), nil
+	}
+
+	for dir, depth := startDir, 0; isWithin(ix.root, dir); dir, depth = filepath.Dir(dir), depth+1 {
+		if depth >= maxDepth {
+			return "", WrapError(ErrFindUpwards, fmt.Errorf("folder search max depth reached"))
+		}
+		if found, ok := ix.FindIn(dir, target); ok {
+			ix.cache.Store(key, found)
+			return found, nil
+		}
+		if dir == ix.root {
+			break
+		}
+	}
+	return "", WrapError(ErrFindUpwards, fmt.Errorf("target '%s' not found from '%s'", target, startDir))
+}
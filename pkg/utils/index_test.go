@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates the given files, with their parent directories, under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":                    "build/\n*.tmp\n",
+		"project.hcl":                   "",
+		"envs/prod/region.hcl":          "",
+		"envs/prod/app/block.yaml":      "",
+		"envs/prod/app/cache.tmp":       "",
+		"envs/prod/app/build/x.hcl":     "",
+		"envs/dev/.gitignore":           "dev.hcl\n",
+		"envs/dev/dev.hcl":              "",
+		"envs/dev/app/block.yaml":       "",
+		"modules/network/vpc/main.tf":   "",
+		"modules/network/other/vpc/a":   "",
+		".git/HEAD":                     "",
+		".terragrunt-cache/project.hcl": "",
+	})
+	ix, err := NewIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		start  string
+		target string
+		depth  int
+		want   string // Relative to root, empty when not found.
+	}{
+		{name: "in the start directory", start: "envs/prod/app", target: "block.yaml", depth: 5, want: "envs/prod/app/block.yaml"},
+		{name: "in a parent", start: "envs/prod/app", target: "region.hcl", depth: 5, want: "envs/prod/region.hcl"},
+		{name: "at the root", start: "envs/prod/app", target: "project.hcl", depth: 5, want: "project.hcl"},
+		{name: "under a parent", start: "envs/prod/app", target: "modules/network", depth: 5, want: "modules/network"},
+		{name: "shallowest match", start: ".", target: "network/vpc", depth: 1, want: "modules/network/vpc"},
+		{name: "max depth", start: "envs/prod/app", target: "project.hcl", depth: 2},
+		{name: "ignored by a nested gitignore", start: "envs/dev/app", target: "dev.hcl", depth: 5},
+		{name: "ignored directory", start: "envs/prod/app", target: "x.hcl", depth: 5},
+		{name: "ignored pattern", start: "envs/prod/app", target: "cache.tmp", depth: 5},
+		{name: "skipped directory", start: ".", target: "HEAD", depth: 5},
+		{name: "missing", start: "envs/prod/app", target: "missing.hcl", depth: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := ix.FindUpwards(filepath.Join(root, tt.start), tt.target, tt.depth)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("FindUpwards() = %s, want not found", found)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindUpwards() error = %v", err)
+			}
+			if want := filepath.Join(root, tt.want); found != want {
+				t.Errorf("FindUpwards() = %s, want %s", found, want)
+			}
+		})
+	}
+}
+
+func TestAddToIndexes(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".git/HEAD":            "",
+		".gitignore":           "*.tmp\n",
+		"envs/prod/block.yaml": "",
+	})
+	ix, err := RepositoryIndex(filepath.Join(root, "envs", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.FindUpwards(filepath.Join(root, "envs", "prod"), "values.hcl", 5); err == nil {
+		t.Fatal("FindUpwards() found values.hcl before it is written")
+	}
+
+	// Files written after the index is built are found once added.
+	writeTree(t, root, map[string]string{
+		"envs/prod/app/values.hcl": "",
+		"envs/prod/app/cache.tmp":  "",
+	})
+	for _, name := range []string{"envs/prod/app/values.hcl", "envs/prod/app/cache.tmp"} {
+		if err := AddToIndexes(filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err := ix.FindUpwards(filepath.Join(root, "envs", "prod"), "app/values.hcl", 5)
+	if err != nil {
+		t.Fatalf("FindUpwards() error = %v", err)
+	}
+	if want := filepath.Join(root, "envs", "prod", "app", "values.hcl"); found != want {
+		t.Errorf("FindUpwards() = %s, want %s", found, want)
+	}
+	if _, ok := ix.FindIn(root, "app"); !ok {
+		t.Error("FindIn() did not find the directory created along with the file")
+	}
+	if found, ok := ix.FindIn(root, "cache.tmp"); ok {
+		t.Errorf("FindIn() = %s, want ignored", found)
+	}
+}
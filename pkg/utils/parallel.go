@@ -0,0 +1,30 @@
+package utils
+
+import "sync"
+
+// Parallel calls fn for every index in [0, n), running at most jobs calls at a time,
+// and returns once all of them have returned. A jobs value lower than 2 runs the calls
+// one after the other. Callers keep results deterministic by storing them by index.
+func Parallel(jobs, n int, fn func(i int)) {
+	if jobs < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, jobs)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operations (RFC 6902).
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// Predefined errors for patch operations.
+var (
+	// ErrPatchOp is returned for an unknown JSON Patch operation.
+	ErrPatchOp = func(op string) error {
+		return fmt.Errorf("unknown patch operation '%s', expected one of: add, remove, replace, move, copy, test", op)
+	}
+
+	// ErrPatchPath is returned when the path of a JSON Patch operation cannot be applied.
+	ErrPatchPath = func(path, reason string) error {
+		return fmt.Errorf("invalid patch path '%s': %s", path, reason)
+	}
+
+	// ErrPatchTest is returned when the value tested by a JSON Patch operation differs.
+	ErrPatchTest = func(path string) error {
+		return fmt.Errorf("patch test failed: '%s' does not hold the expected value", path)
+	}
+)
+
+// PatchOperation is a JSON Patch operation (RFC 6902). Paths are JSON Pointers (RFC 6901).
+type PatchOperation struct {
+	Op    string      // Operation: add, remove, replace, move, copy or test.
+	Path  string      // Location the operation applies to.
+	From  string      // Source location of move and copy.
+	Value interface{} // Value of add, replace and test.
+}
+
+// ApplyPatch applies op to doc, as produced by a YAML or JSON unmarshaller, and returns the
+// patched document. doc is left untouched.
+func ApplyPatch(doc interface{}, op PatchOperation) (interface{}, error) {
+	doc = DeepCopy(doc)
+	path, err := pointerTokens(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case PatchAdd:
+		return setAt(doc, path, op.Path, DeepCopy(op.Value), true)
+	case PatchRemove:
+		doc, _, err := removeAt(doc, path, op.Path)
+		return doc, err
+	case PatchReplace:
+		return setAt(doc, path, op.Path, DeepCopy(op.Value), false)
+	case PatchMove, PatchCopy:
+		from, err := pointerTokens(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == PatchMove {
+			doc, value, err = removeAt(doc, from, op.From)
+		} else {
+			value, err = getAt(doc, from, op.From)
+			value = DeepCopy(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return setAt(doc, path, op.Path, value, true)
+	case PatchTest:
+		value, err := getAt(doc, path, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, op.Value) {
+			return nil, ErrPatchTest(op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, ErrPatchOp(op.Op)
+	}
+}
+
+// jsonEqual reports whether a and b are the same JSON value. Both are round-tripped through JSON
+// first, so that numbers compare by value whatever their Go type, such as 3 and 3.0.
+func jsonEqual(a, b interface{}) bool {
+	normalized := make([]interface{}, 2)
+	for i, value := range []interface{}{a, b} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return reflect.DeepEqual(a, b)
+		}
+		if err := json.Unmarshal(data, &normalized[i]); err != nil {
+			return reflect.DeepEqual(a, b)
+		}
+	}
+	return reflect.DeepEqual(normalized[0], normalized[1])
+}
+
+// pointerTokens returns the reference tokens of a JSON Pointer, unescaped.
+func pointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrPatchPath(pointer, "must be empty or start with '/'")
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getAt returns the value of node at the given tokens of pointer.
+func getAt(node interface{}, tokens []string, pointer string) (interface{}, error) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			value, ok := n[token]
+			if !ok {
+				return nil, ErrPatchPath(pointer, fmt.Sprintf("key '%s' not found", token))
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(n)-1, pointer)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, ErrPatchPath(pointer, fmt.Sprintf("'%s' is not in a map or a list", token))
+		}
+	}
+	return node, nil
+}
+
+// setAt sets the value of node at the given tokens of pointer and returns node. In insert mode,
+// map keys are added and list items are inserted, "-" appending to the list; otherwise the value
+// must exist and is replaced.
+func setAt(node interface{}, tokens []string, pointer string, value interface{}, insert bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, last := tokens[0], len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok && (!last || !insert) {
+			return nil, ErrPatchPath(pointer, fmt.Sprintf("key '%s' not found", token))
+		}
+		if last {
+			n[token] = value
+			return n, nil
+		}
+		child, err := setAt(child, tokens[1:], pointer, value, insert)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = child
+		return n, nil
+	case []interface{}:
+		if last && insert {
+			index := len(n)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(n), pointer); err != nil {
+					return nil, err
+				}
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(token, len(n)-1, pointer)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			n[index] = value
+			return n, nil
+		}
+		child, err := setAt(n[index], tokens[1:], pointer, value, insert)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = child
+		return n, nil
+	default:
+		return nil, ErrPatchPath(pointer, fmt.Sprintf("'%s' is not in a map or a list", token))
+	}
+}
+
+// removeAt removes the value of node at the given tokens of pointer, and returns node and the removed value.
+func removeAt(node interface{}, tokens []string, pointer string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil, ErrPatchPath(pointer, "the whole document cannot be removed")
+	}
+	token, last := tokens[0], len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, ErrPatchPath(pointer, fmt.Sprintf("key '%s' not found", token))
+		}
+		if last {
+			delete(n, token)
+			return n, child, nil
+		}
+		child, removed, err := removeAt(child, tokens[1:], pointer)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = child
+		return n, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n)-1, pointer)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+		child, removed, err := removeAt(n[index], tokens[1:], pointer)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[index] = child
+		return n, removed, nil
+	default:
+		return nil, nil, ErrPatchPath(pointer, fmt.Sprintf("'%s' is not in a map or a list", token))
+	}
+}
+
+// arrayIndex parses a list index of pointer, which must be between 0 and max.
+func arrayIndex(token string, max int, pointer string) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrPatchPath(pointer, fmt.Sprintf("invalid list index '%s'", token))
+	}
+	return index, nil
+}
+
+// DeepCopy returns a copy of value, as produced by a YAML or JSON unmarshaller, sharing no map or list with it.
+func DeepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = DeepCopy(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = DeepCopy(item)
+		}
+		return c
+	default:
+		return value
+	}
+}
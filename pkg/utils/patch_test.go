@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":     "app",
+			"replicas": 3,
+			"ratio":    0.5,
+			"tags":     []interface{}{"a", "b"},
+			"labels":   map[string]interface{}{"team": "data", "a/b": "x", "m~n": "y"},
+		}
+	}
+	tests := []struct {
+		name    string
+		op      PatchOperation
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "add a key",
+			op:   PatchOperation{Op: PatchAdd, Path: "/labels/env", Value: "prod"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b"}, "labels": map[string]interface{}{"team": "data", "a/b": "x", "m~n": "y", "env": "prod"}},
+		},
+		{
+			name: "insert in a list",
+			op:   PatchOperation{Op: PatchAdd, Path: "/tags/1", Value: "c"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "c", "b"}, "labels": map[string]interface{}{"team": "data", "a/b": "x", "m~n": "y"}},
+		},
+		{
+			name: "append to a list",
+			op:   PatchOperation{Op: PatchAdd, Path: "/tags/-", Value: "c"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b", "c"}, "labels": map[string]interface{}{"team": "data", "a/b": "x", "m~n": "y"}},
+		},
+		{
+			name: "remove an escaped key",
+			op:   PatchOperation{Op: PatchRemove, Path: "/labels/a~1b"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b"}, "labels": map[string]interface{}{"team": "data", "m~n": "y"}},
+		},
+		{
+			name: "replace a value",
+			op:   PatchOperation{Op: PatchReplace, Path: "/labels/m~0n", Value: "z"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b"}, "labels": map[string]interface{}{"team": "data", "a/b": "x", "m~n": "z"}},
+		},
+		{
+			name: "move a value",
+			op:   PatchOperation{Op: PatchMove, From: "/labels/team", Path: "/team"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b"}, "labels": map[string]interface{}{"a/b": "x", "m~n": "y"}, "team": "data"},
+		},
+		{
+			name: "copy a value",
+			op:   PatchOperation{Op: PatchCopy, From: "/tags/0", Path: "/first"},
+			want: map[string]interface{}{"name": "app", "replicas": 3, "ratio": 0.5, "tags": []interface{}{"a", "b"}, "labels": map[string]interface{}{"team": "data", "a/b": "x", "m~n": "y"}, "first": "a"},
+		},
+		{
+			name: "test an integer against a float",
+			op:   PatchOperation{Op: PatchTest, Path: "/replicas", Value: 3.0},
+			want: doc(),
+		},
+		{
+			name: "test a float against an integer type",
+			op:   PatchOperation{Op: PatchTest, Path: "/ratio", Value: 0.5},
+			want: doc(),
+		},
+		{
+			name: "test a list",
+			op:   PatchOperation{Op: PatchTest, Path: "/tags", Value: []interface{}{"a", "b"}},
+			want: doc(),
+		},
+		{name: "test a different value", op: PatchOperation{Op: PatchTest, Path: "/replicas", Value: 4}, wantErr: true},
+		{name: "replace a missing key", op: PatchOperation{Op: PatchReplace, Path: "/missing", Value: 1}, wantErr: true},
+		{name: "remove out of range", op: PatchOperation{Op: PatchRemove, Path: "/tags/2"}, wantErr: true},
+		{name: "path without slash", op: PatchOperation{Op: PatchAdd, Path: "name", Value: 1}, wantErr: true},
+		{name: "unknown operation", op: PatchOperation{Op: "merge", Path: "/name"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := doc()
+			got, err := ApplyPatch(original, tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(original, doc()) {
+				t.Errorf("ApplyPatch() modified its input: %#v", original)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyPatch() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// IgnoredDirs lists the directory names that are never walked into when scanning a repository.
+var IgnoredDirs = []string{".git", ".terragrunt-cache", "node_modules"}
+
+// ignoreFile holds the rules of a .gitignore file and the directory it applies to.
+type ignoreFile struct {
+	dir   string
+	rules *ignore.GitIgnore
+}
+
+// WalkRepository walks the file tree rooted at root like filepath.WalkDir, skipping the
+// IgnoredDirs and every path matched by a .gitignore file. The .gitignore files found in
+// root, in its sub-directories and in its parents up to repoRoot are all taken into account.
+func WalkRepository(repoRoot, root string, fn fs.WalkDirFunc) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	// Load the .gitignore files of the parents of root, from the repository root down.
+	var ignoreFiles []ignoreFile
+	if repoRoot != "" {
+		if rel, err := filepath.Rel(repoRoot, root); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			dir := repoRoot
+			for _, part := range strings.Split(rel, string(filepath.Separator)) {
+				ignoreFiles = appendIgnoreFile(ignoreFiles, dir)
+				dir = filepath.Join(dir, part)
+			}
+		}
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(path, d, err)
+		}
+
+		// Forget the .gitignore files of the directories that were left.
+		for len(ignoreFiles) > 0 && !isWithin(ignoreFiles[len(ignoreFiles)-1].dir, path) {
+			ignoreFiles = ignoreFiles[:len(ignoreFiles)-1]
+		}
+
+		if d.IsDir() {
+			for _, name := range IgnoredDirs {
+				if d.Name() == name {
+					return filepath.SkipDir
+				}
+			}
+		}
+		if isIgnored(ignoreFiles, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			ignoreFiles = appendIgnoreFile(ignoreFiles, path)
+		}
+		return fn(path, d, nil)
+	})
+}
+
+// appendIgnoreFile appends the rules of the .gitignore file of dir, if there is one.
+func appendIgnoreFile(ignoreFiles []ignoreFile, dir string) []ignoreFile {
+	rules, err := ignore.CompileIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return ignoreFiles
+	}
+	return append(ignoreFiles, ignoreFile{dir: dir, rules: rules})
+}
+
+// isIgnored reports whether path is matched by one of the given .gitignore files.
+func isIgnored(ignoreFiles []ignoreFile, path string, isDir bool) bool {
+	for _, f := range ignoreFiles {
+		rel, err := filepath.Rel(f.dir, path)
+		if err != nil || rel == "." {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if f.rules.MatchesPath(rel) || (isDir && f.rules.MatchesPath(rel+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is dir or one of its descendants. Both are compared cleaned.
+func isWithin(dir, path string) bool {
+	dir, path = filepath.Clean(dir), filepath.Clean(path)
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(os.PathSeparator)) {
+		dir += string(os.PathSeparator)
+	}
+	return strings.HasPrefix(path, dir)
+}